@@ -2,9 +2,13 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog/log"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/ratelimit"
 )
 
 type Config struct {
@@ -13,7 +17,11 @@ type Config struct {
 
 	ServerHost string
 	ServerPort string
+	// ProxyHeader holds the client IP on requests from TrustedProxies.
+	ProxyHeader    string
+	TrustedProxies []string
 
+	DBDriver   string
 	DBHost     string
 	DBPort     string
 	DBUser     string
@@ -21,6 +29,74 @@ type Config struct {
 	DBName     string
 	DBSSLMode  string
 	DBTimezone string
+	DBPath     string
+	// DBReplicas holds the data source names of the read replicas.
+	DBReplicas []string
+
+	DBConnectAttempts   int
+	DBConnectBackoff    time.Duration
+	DBConnectMaxBackoff time.Duration
+
+	DBMaxOpenConns     int
+	DBMaxIdleConns     int
+	DBConnMaxLifetime  time.Duration
+	DBConnMaxIdleTime  time.Duration
+	DBStatementTimeout time.Duration
+
+	DBMigrateOnStart bool
+	DBAutoMigrate    bool
+
+	CursorSecret string
+
+	RequireIfMatch bool
+
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+
+	// ImportSyncRows is the number of rows above which imports run in the background.
+	ImportSyncRows int
+	// ImportMaxSize is the largest accepted upload in bytes, other requests
+	// keep the default body limit.
+	ImportMaxSize int
+
+	// ExportMaxConcurrent is the number of exports streaming at once, each
+	// one holds a database connection.
+	ExportMaxConcurrent int
+	// ExportWriteTimeout bounds every write of an export to a slow client.
+	ExportWriteTimeout time.Duration
+
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	RateLimitEnabled bool
+	RateLimitStore   string
+	RedisURL         string
+	// RateLimits holds the limit of every route group by identity.
+	RateLimits map[string]map[string]ratelimit.Limit
+
+	MetricsEnabled         bool
+	MetricsRefreshInterval time.Duration
+
+	TracingExporter    string
+	TracingEndpoint    string
+	TracingInsecure    bool
+	TracingSampleRatio float64
+
+	HealthCheckTimeout time.Duration
+
+	ShutdownDelay   time.Duration
+	ShutdownTimeout time.Duration
+}
+
+// defaultRateLimits are the limits by route group and identity, each one can
+// be overridden with RATE_LIMIT_<GROUP>_<IDENTITY>, e.g. RATE_LIMIT_SEARCH_IP=30/1m.
+var defaultRateLimits = map[string]map[string]string{
+	ratelimit.GroupDefault: {ratelimit.IdentityIP: "120/1m", ratelimit.IdentityUser: "300/1m", ratelimit.IdentityAPIKey: "1200/1m"},
+	ratelimit.GroupSearch:  {ratelimit.IdentityIP: "30/1m", ratelimit.IdentityUser: "60/1m", ratelimit.IdentityAPIKey: "300/1m"},
+	ratelimit.GroupAuth:    {ratelimit.IdentityIP: "10/1m", ratelimit.IdentityUser: "10/1m", ratelimit.IdentityAPIKey: "10/1m"},
+	// failed authentications are only counted by IP, they have no identity
+	ratelimit.GroupAuthFailure: {ratelimit.IdentityIP: "20/1m"},
 }
 
 func Load() *Config {
@@ -40,7 +116,12 @@ func Load() *Config {
 
 		ServerHost: getEnv("SERVER_HOST", "localhost"),
 		ServerPort: getEnv("SERVER_PORT", "3000"),
+		// client IPs identify rate limit buckets, they are only read from
+		// the proxy header when the request comes from a trusted proxy
+		ProxyHeader:    getEnv("PROXY_HEADER", ""),
+		TrustedProxies: getEnvList("TRUSTED_PROXIES"),
 
+		DBDriver:   getEnv("DB_DRIVER", "postgres"),
 		DBHost:     getEnv("DB_HOST", "localhost"),
 		DBPort:     getEnv("DB_PORT", "5432"),
 		DBUser:     getEnv("DB_USER", "postgres"),
@@ -48,7 +129,81 @@ func Load() *Config {
 		DBName:     getEnv("DB_NAME", "movie_app"),
 		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
 		DBTimezone: getEnv("DB_TIMEZONE", "UTC"),
+		DBPath:     getEnv("DB_PATH", "movie_app.db"),
+		DBReplicas: getEnvList("DB_REPLICAS"),
+
+		DBConnectAttempts:   getEnvInt("DB_CONNECT_ATTEMPTS", 10),
+		DBConnectBackoff:    getEnvDuration("DB_CONNECT_BACKOFF", 500*time.Millisecond),
+		DBConnectMaxBackoff: getEnvDuration("DB_CONNECT_MAX_BACKOFF", 30*time.Second),
+
+		DBMaxOpenConns:     getEnvInt("DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns:     getEnvInt("DB_MAX_IDLE_CONNS", 10),
+		DBConnMaxLifetime:  getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		DBConnMaxIdleTime:  getEnvDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+		DBStatementTimeout: getEnvDuration("DB_STATEMENT_TIMEOUT", 30*time.Second),
+
+		DBMigrateOnStart: getEnvBool("DB_MIGRATE_ON_START", true),
+		// GORM AutoMigrate is only allowed in development
+		DBAutoMigrate: getEnv("APP_ENV", "development") == "development" && getEnvBool("DB_AUTO_MIGRATE", false),
+
+		CursorSecret: getEnv("CURSOR_SECRET", ""),
+
+		RequireIfMatch: getEnvBool("REQUIRE_IF_MATCH", false),
+
+		TrashRetention:     getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+		TrashPurgeInterval: getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour),
+
+		ImportSyncRows: getEnvInt("IMPORT_SYNC_ROWS", 500),
+		ImportMaxSize:  getEnvInt("IMPORT_MAX_SIZE_MB", 32) * 1024 * 1024,
+
+		ExportMaxConcurrent: getEnvInt("EXPORT_MAX_CONCURRENT", 2),
+		ExportWriteTimeout:  getEnvDuration("EXPORT_WRITE_TIMEOUT", 30*time.Second),
+
+		JWTSecret:       getEnv("JWT_SECRET", ""),
+		AccessTokenTTL:  getEnvDuration("JWT_ACCESS_TTL", 15*time.Minute),
+		RefreshTokenTTL: getEnvDuration("JWT_REFRESH_TTL", 7*24*time.Hour),
+
+		RateLimitEnabled: getEnvBool("RATE_LIMIT_ENABLED", true),
+		RateLimitStore:   getEnv("RATE_LIMIT_STORE", "memory"),
+		RedisURL:         getEnv("REDIS_URL", "redis://localhost:6379/0"),
+		RateLimits:       getRateLimits(),
+
+		MetricsEnabled:         getEnvBool("METRICS_ENABLED", true),
+		MetricsRefreshInterval: getEnvDuration("METRICS_REFRESH_INTERVAL", time.Minute),
+
+		TracingExporter:    getEnv("TRACING_EXPORTER", "none"),
+		TracingEndpoint:    getEnv("TRACING_ENDPOINT", ""),
+		TracingInsecure:    getEnvBool("TRACING_INSECURE", false),
+		TracingSampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+
+		HealthCheckTimeout: getEnvDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+
+		ShutdownDelay:   getEnvDuration("SHUTDOWN_DELAY", 5*time.Second),
+		ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 15*time.Second),
+	}
+}
+
+func getRateLimits() map[string]map[string]ratelimit.Limit {
+	limits := make(map[string]map[string]ratelimit.Limit, len(defaultRateLimits))
+	for group, identities := range defaultRateLimits {
+		limits[group] = make(map[string]ratelimit.Limit, len(identities))
+		for identity, defaultValue := range identities {
+			key := "RATE_LIMIT_" + strings.ToUpper(group+"_"+identity)
+			limit, err := ratelimit.ParseLimit(getEnv(key, defaultValue))
+			if err != nil {
+				log.Warn().Err(err).Str("key", key).Msg("Invalid rate limit environment variable, using default")
+				limit, _ = ratelimit.ParseLimit(defaultValue)
+			}
+			limits[group][identity] = limit
+		}
 	}
+	return limits
+}
+
+// IsProduction reports whether the app runs in production, where settings
+// with development fallbacks are required.
+func (c *Config) IsProduction() bool {
+	return c.AppEnv == "production"
 }
 
 func getEnv(key, defaultValue string) string {
@@ -57,3 +212,62 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("Invalid boolean environment variable, using default")
+			return defaultValue
+		}
+		return parsed
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("Invalid integer environment variable, using default")
+			return defaultValue
+		}
+		return parsed
+	}
+	return defaultValue
+}
+
+// getEnvList splits a comma-separated variable, ignoring empty items.
+func getEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("Invalid float environment variable, using default")
+			return defaultValue
+		}
+		return parsed
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("Invalid duration environment variable, using default")
+			return defaultValue
+		}
+		return parsed
+	}
+	return defaultValue
+}
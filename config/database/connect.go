@@ -1,34 +1,115 @@
 package database
 
 import (
-	"fmt"
+	"context"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/zdacoder/go-fiber-movie-app-api/config"
-	"gorm.io/driver/postgres"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/health"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/logger"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/metrics"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/shutdown"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/telemetry"
 	"gorm.io/gorm"
+	"gorm.io/plugin/opentelemetry/tracing"
 )
 
 var DB *gorm.DB
 
 func Connect(config *config.Config) {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=%s",
-		config.DBHost,
-		config.DBPort,
-		config.DBUser,
-		config.DBPassword,
-		config.DBName,
-		config.DBSSLMode,
-		config.DBTimezone,
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-
+	dsn, err := dsn(config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	dialector, err := dialector(config.DBDriver, dsn, config.DBStatementTimeout)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+
+	db, err := open(config, dialector)
+	if err != nil {
+		log.Fatal().Err(err).Int("attempts", config.DBConnectAttempts).Msg("Failed to connect to database")
 	} else {
-		log.Info().Msg("Database connection established")
+		log.Info().Str("driver", config.DBDriver).Msg("Database connection established")
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	sqlDB.SetMaxOpenConns(config.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.DBConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.DBConnMaxIdleTime)
+
+	// SQLite allows a single writer, and every connection to an in-memory database opens a new one
+	if config.DBDriver == DriverSQLite {
+		sqlDB.SetMaxOpenConns(1)
 	}
 
+	// route the reads that opted in to the replicas, see ReadFromReplica
+	if err := useReplicas(db, config); err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database replicas")
+	}
+
+	// trace queries as children of the span of their context
+	if telemetry.Enabled() {
+		plugin := tracing.NewPlugin(tracing.WithDBName(config.DBName), tracing.WithoutMetrics(), tracing.WithoutQueryVariables())
+		if err := db.Use(plugin); err != nil {
+			log.Fatal().Err(err).Msg("Failed to register database tracing")
+		}
+	}
+
+	// record query durations and connection pool statistics
+	if config.MetricsEnabled {
+		if err := db.Use(&metrics.GormPlugin{DBName: config.DBName}); err != nil {
+			log.Fatal().Err(err).Msg("Failed to register database metrics")
+		}
+	}
+
+	// readiness fails while the database is unreachable
+	health.Register("database", func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+
+	// close the pool once the server stopped and the jobs using it are done
+	shutdown.Register("database", func(context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	})
+
 	DB = db
 }
+
+// open opens the database, retrying with exponential backoff while it is
+// unreachable, e.g. while it is still starting next to the app.
+func open(config *config.Config, dialector gorm.Dialector) (*gorm.DB, error) {
+	backoff := config.DBConnectBackoff
+	for attempt := 1; ; attempt++ {
+		db, err := gorm.Open(dialector, &gorm.Config{
+			Logger: logger.NewGormLogger(),
+		})
+		if err == nil || attempt >= config.DBConnectAttempts {
+			return db, err
+		}
+
+		// drop the pool of the failed attempt
+		if db != nil {
+			if sqlDB, err := db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		}
+
+		log.Warn().Err(err).Int("attempt", attempt).Dur("retry_in", backoff).Msg("Database is unreachable, retrying")
+		time.Sleep(backoff)
+		backoff = min(backoff*2, config.DBConnectMaxBackoff)
+	}
+}
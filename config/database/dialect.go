@@ -0,0 +1,147 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Supported database drivers, named as their GORM dialectors.
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+)
+
+// Drivers lists the supported database drivers.
+var Drivers = []string{DriverPostgres, DriverMySQL, DriverSQLite}
+
+// dsn returns the data source name of the primary database of the configured driver.
+func dsn(config *config.Config) (string, error) {
+	switch config.DBDriver {
+	case DriverPostgres:
+		return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=%s",
+			config.DBHost,
+			config.DBPort,
+			config.DBUser,
+			config.DBPassword,
+			config.DBName,
+			config.DBSSLMode,
+			config.DBTimezone,
+		), nil
+	case DriverMySQL:
+		// migration files hold several statements
+		return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=true&loc=%s&multiStatements=true",
+			config.DBUser,
+			config.DBPassword,
+			config.DBHost,
+			config.DBPort,
+			config.DBName,
+			url.QueryEscape(config.DBTimezone),
+		), nil
+	case DriverSQLite:
+		// SQLite leaves foreign keys off unless asked, and fails at once on a locked database
+		return fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", config.DBPath), nil
+	default:
+		return "", fmt.Errorf("unsupported database driver %q, expected one of %s", config.DBDriver, strings.Join(Drivers, ", "))
+	}
+}
+
+// dialector returns the GORM dialector of the driver for a data source name,
+// which the server makes cancel statements running longer than the timeout.
+// SQLite has no statement timeout.
+func dialector(driver, dsn string, timeout time.Duration) (gorm.Dialector, error) {
+	switch driver {
+	case DriverPostgres:
+		if timeout > 0 && strings.Contains(dsn, "://") {
+			dsn = withQueryParam(dsn, "statement_timeout", milliseconds(timeout))
+		} else if timeout > 0 {
+			dsn += " statement_timeout=" + milliseconds(timeout)
+		}
+		return postgres.Open(dsn), nil
+	case DriverMySQL:
+		if timeout > 0 {
+			dsn = withQueryParam(dsn, "max_execution_time", milliseconds(timeout))
+		}
+		return mysql.Open(dsn), nil
+	case DriverSQLite:
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q, expected one of %s", driver, strings.Join(Drivers, ", "))
+	}
+}
+
+// withQueryParam appends a parameter to the query string of a data source name.
+func withQueryParam(dsn, key, value string) string {
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + key + "=" + value
+	}
+	return dsn + "?" + key + "=" + value
+}
+
+func milliseconds(d time.Duration) string {
+	return strconv.FormatInt(d.Milliseconds(), 10)
+}
+
+// Driver returns the driver of the database connection.
+func Driver() string {
+	return DB.Dialector.Name()
+}
+
+// IsPostgres reports whether db is a PostgreSQL connection, the only one
+// with full-text search and trigram matching. Other databases fall back to
+// LIKE matching, with the trigrams of the autocomplete compared by the API.
+func IsPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == DriverPostgres
+}
+
+// ILike returns a case-insensitive LIKE condition on the column, taking the
+// pattern as argument. Backslash escapes wildcards on every driver.
+func ILike(db *gorm.DB, column string) string {
+	switch db.Dialector.Name() {
+	case DriverPostgres:
+		return column + " ILIKE ?"
+	case DriverSQLite:
+		return "LOWER(" + column + ") LIKE LOWER(?) ESCAPE '\\'"
+	default:
+		return "LOWER(" + column + ") LIKE LOWER(?)"
+	}
+}
+
+// EscapeLike escapes the LIKE wildcards in user input.
+func EscapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// Year returns an expression extracting the year of a date column as an integer.
+func Year(db *gorm.DB, column string) string {
+	switch db.Dialector.Name() {
+	case DriverMySQL:
+		return "YEAR(" + column + ")"
+	case DriverSQLite:
+		return "CAST(strftime('%Y', " + column + ") AS INTEGER)"
+	default:
+		return "EXTRACT(YEAR FROM " + column + ")::int"
+	}
+}
+
+// GroupConcat returns an aggregate joining the values of a column with the
+// separator, in ascending order except on SQLite, which keeps the scan order.
+func GroupConcat(db *gorm.DB, column, separator string) string {
+	switch db.Dialector.Name() {
+	case DriverMySQL:
+		return "GROUP_CONCAT(" + column + " ORDER BY " + column + " SEPARATOR '" + separator + "')"
+	case DriverSQLite:
+		return "GROUP_CONCAT(" + column + ", '" + separator + "')"
+	default:
+		return "STRING_AGG(" + column + ", '" + separator + "' ORDER BY " + column + ")"
+	}
+}
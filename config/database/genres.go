@@ -0,0 +1,82 @@
+package database
+
+import (
+	"encoding/json"
+
+	"github.com/rs/zerolog/log"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// genreAliases maps spellings found in the legacy genre JSON to a canonical genre
+var genreAliases = map[string]struct{ slug, name string }{
+	"sci-fi":          {"science-fiction", "Science Fiction"},
+	"scifi":           {"science-fiction", "Science Fiction"},
+	"sf":              {"science-fiction", "Science Fiction"},
+	"science-fiction": {"science-fiction", "Science Fiction"},
+	"rom-com":         {"romantic-comedy", "Romantic Comedy"},
+	"romcom":          {"romantic-comedy", "Romantic Comedy"},
+	"biopic":          {"biography", "Biography"},
+	"doc":             {"documentary", "Documentary"},
+}
+
+// migrateMovieGenres moves the free-form movies.genre JSON column into the
+// genres and movie_genres tables and drops the column afterwards. Only
+// databases created before the versioned migrations, on PostgreSQL, have it.
+func migrateMovieGenres(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn("movies", "genre") {
+		return nil
+	}
+
+	var rows []struct {
+		ID    uint
+		Genre string
+	}
+	if err := tx.Table("movies").Select("id, genre::text AS genre").Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	genreIDs := map[string]uint{}
+	for _, row := range rows {
+		var names []string
+		if err := json.Unmarshal([]byte(row.Genre), &names); err != nil {
+			log.Warn().Err(err).Uint("movie_id", row.ID).Msg("Skipping unreadable movie genre")
+			continue
+		}
+
+		for _, name := range names {
+			slug := utils.Slugify(name)
+			if slug == "" {
+				continue
+			}
+			if alias, ok := genreAliases[slug]; ok {
+				slug, name = alias.slug, alias.name
+			}
+
+			id, ok := genreIDs[slug]
+			if !ok {
+				err := tx.Raw(
+					"INSERT INTO genres (name, slug, created_at, updated_at) VALUES (?, ?, NOW(), NOW()) "+
+						"ON CONFLICT (slug) DO UPDATE SET slug = EXCLUDED.slug RETURNING id",
+					name, slug,
+				).Scan(&id).Error
+				if err != nil {
+					return err
+				}
+				genreIDs[slug] = id
+			}
+
+			err := tx.Exec(
+				"INSERT INTO movie_genres (movie_id, genre_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+				row.ID, id,
+			).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Info().Int("movies", len(rows)).Int("genres", len(genreIDs)).Msg("Movie genres migrated to genres table")
+
+	return tx.Exec("ALTER TABLE movies DROP COLUMN genre").Error
+}
@@ -1,15 +1,76 @@
 package database
 
-import "github.com/rs/zerolog/log"
+import (
+	"context"
+	"fmt"
 
-func Migrate(models ...interface{}) {
+	"github.com/rs/zerolog/log"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/health"
+	"gorm.io/gorm"
+)
+
+// extensions required by the models, created before the tables are migrated
+var extensions = []string{"pg_trgm"}
+
+// Migrate applies the pending versioned migrations and makes readiness
+// fail while the schema is not at the latest version.
+func Migrate() {
+	if DB == nil {
+		log.Fatal().Msg("Database connection is not established")
+	}
+
+	applied, err := MigrateUp(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("Database migration failed")
+	}
+
+	health.Register("migrations", CheckMigrations)
+
+	log.Info().Int("applied", applied).Msg("Database migration completed successfully")
+}
+
+// AutoMigrate creates and alters the tables of the models with GORM instead
+// of the versioned migrations. It is meant for trying model changes out in
+// development before writing their migration, and never drops anything.
+func AutoMigrate(models ...interface{}) {
 	if DB == nil {
 		log.Fatal().Msg("Database connection is not established")
 	}
 
+	// the models declare PostgreSQL search columns and indexes
+	if !IsPostgres(DB) {
+		log.Fatal().Str("driver", Driver()).Msg("Auto-migration requires PostgreSQL, use the versioned migrations")
+	}
+
+	for _, extension := range extensions {
+		if err := DB.Exec(fmt.Sprintf("CREATE EXTENSION IF NOT EXISTS %s", extension)).Error; err != nil {
+			log.Fatal().Err(err).Str("extension", extension).Msg("Failed to create database extension")
+		}
+	}
+
 	if err := DB.AutoMigrate(models...); err != nil {
 		log.Fatal().Err(err).Msg("Database migration failed")
 	}
 
-	log.Info().Msg("Database migration completed successfully")
+	// readiness fails while a migrated table is missing
+	health.Register("migrations", func(ctx context.Context) error {
+		return checkTables(ctx, models)
+	})
+
+	log.Warn().Msg("Database auto-migrated from the models, versioned migrations were skipped")
+}
+
+// checkTables reports the first table of the models missing in the database.
+func checkTables(ctx context.Context, models []interface{}) error {
+	migrator := DB.WithContext(ctx).Migrator()
+	for _, model := range models {
+		if !migrator.HasTable(model) {
+			statement := &gorm.Statement{DB: DB}
+			if err := statement.Parse(model); err != nil {
+				return err
+			}
+			return fmt.Errorf("table %s is missing", statement.Table)
+		}
+	}
+	return nil
 }
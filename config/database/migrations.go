@@ -0,0 +1,421 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// MigrationsDir is where migration files are created, relative to the
+// repository root, in one directory per driver. They are embedded in the
+// binary at build time.
+const MigrationsDir = "config/database/migrations"
+
+// migrationLockID and migrationLockName identify the lock held while
+// migrating on PostgreSQL and MySQL, so that only one instance migrates at a
+// time. SQLite serializes writers on its own.
+const (
+	migrationLockID   int64 = 7_215_020_190
+	migrationLockName       = "movie_app_migrations"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationFilePattern matches <version>_<name>.<up|down>.sql.
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// goMigrations are the migrations that need more than SQL, shared by every driver.
+var goMigrations = []Migration{
+	{Version: 2, Name: "movie_genres", Up: migrateMovieGenres, Down: noMigration},
+}
+
+// Migration is a versioned schema change. Each direction runs in a
+// transaction, together with the update of schema_migrations. MySQL commits
+// schema changes implicitly, a failed migration may be partly applied there.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration records an applied migration.
+type SchemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// MigrationStatus tells whether a migration has been applied.
+type MigrationStatus struct {
+	Version   uint
+	Name      string
+	AppliedAt *time.Time
+	// Unknown marks applied migrations that this binary does not know.
+	Unknown bool
+}
+
+// Migrations returns every known migration of the driver, ordered by version.
+func Migrations(driver string) ([]Migration, error) {
+	byVersion := map[uint]*Migration{}
+	for _, migration := range goMigrations {
+		byVersion[migration.Version] = &migration
+	}
+
+	dir := path.Join("migrations", driver)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for driver %s: %w", driver, err)
+	}
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[uint(version)]
+		if !ok {
+			migration = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[migration.Version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, match[2])
+		}
+
+		run, err := sqlMigration(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		direction := &migration.Up
+		if match[3] == "down" {
+			direction = &migration.Down
+		}
+		if *direction != nil {
+			return nil, fmt.Errorf("migration %d has more than one %s step", version, match[3])
+		}
+		*direction = run
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == nil || migration.Down == nil {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down step", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return int(a.Version) - int(b.Version)
+	})
+	return migrations, nil
+}
+
+// LatestMigrationVersion returns the version the schema of the driver is at
+// once every known migration has been applied.
+func LatestMigrationVersion(driver string) (uint, error) {
+	migrations, err := Migrations(driver)
+	if err != nil || len(migrations) == 0 {
+		return 0, err
+	}
+	return migrations[len(migrations)-1].Version, nil
+}
+
+// MigrateUp applies the pending migrations in order and returns how many ran.
+func MigrateUp(ctx context.Context) (int, error) {
+	migrations, err := Migrations(Driver())
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	err = withMigrationLock(ctx, func(db *gorm.DB) error {
+		versions, err := appliedVersions(db)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := LiftStatementTimeout(tx); err != nil {
+					return err
+				}
+				if err := migration.Up(tx); err != nil {
+					return err
+				}
+				return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			log.Info().Uint("version", migration.Version).Str("name", migration.Name).Msg("Migration applied")
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown reverts the given number of applied migrations, latest first,
+// and returns how many ran.
+func MigrateDown(ctx context.Context, steps int) (int, error) {
+	migrations, err := Migrations(Driver())
+	if err != nil {
+		return 0, err
+	}
+
+	reverted := 0
+	err = withMigrationLock(ctx, func(db *gorm.DB) error {
+		versions, err := appliedVersions(db)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := migrations[i]
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := LiftStatementTimeout(tx); err != nil {
+					return err
+				}
+				if err := migration.Down(tx); err != nil {
+					return err
+				}
+				return tx.Delete(&SchemaMigration{}, migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			log.Info().Uint("version", migration.Version).Str("name", migration.Name).Msg("Migration reverted")
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// MigrationStatuses lists every known migration and the applied ones this
+// binary does not know, ordered by version.
+func MigrationStatuses(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := Migrations(Driver())
+	if err != nil {
+		return nil, err
+	}
+
+	// nothing has been applied before the first migration
+	versions := map[uint]SchemaMigration{}
+	db := DB.WithContext(ctx)
+	if db.Migrator().HasTable(&SchemaMigration{}) {
+		if versions, err = appliedVersions(db); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if applied, ok := versions[migration.Version]; ok {
+			status.AppliedAt = &applied.AppliedAt
+			delete(versions, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, applied := range versions {
+		statuses = append(statuses, MigrationStatus{Version: applied.Version, Name: applied.Name, AppliedAt: &applied.AppliedAt, Unknown: true})
+	}
+	slices.SortFunc(statuses, func(a, b MigrationStatus) int {
+		return int(a.Version) - int(b.Version)
+	})
+	return statuses, nil
+}
+
+// CreateMigration creates an empty up and down SQL file pair for every
+// driver in dir, with the version following the latest known one, and
+// returns their paths.
+func CreateMigration(dir, name string) ([]string, error) {
+	name = strings.Trim(regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("migration name is empty")
+	}
+
+	// files created since the last build are not embedded yet
+	var latest uint
+	for _, driver := range Drivers {
+		version, err := LatestMigrationVersion(driver)
+		if err != nil {
+			return nil, err
+		}
+		latest = max(latest, version)
+
+		entries, err := os.ReadDir(filepath.Join(dir, driver))
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if match := migrationFilePattern.FindStringSubmatch(entry.Name()); match != nil {
+				if version, err := strconv.ParseUint(match[1], 10, 32); err == nil && uint(version) > latest {
+					latest = uint(version)
+				}
+			}
+		}
+	}
+
+	var files []string
+	for _, driver := range Drivers {
+		base := filepath.Join(dir, driver, fmt.Sprintf("%04d_%s", latest+1, name))
+		for _, file := range []string{base + ".up.sql", base + ".down.sql"} {
+			content := fmt.Sprintf("-- %s\n", strings.ReplaceAll(name, "_", " "))
+			if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+				return nil, err
+			}
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// CheckMigrations reports an error unless every known migration has been
+// applied and no unknown one has.
+func CheckMigrations(ctx context.Context) error {
+	latest, err := LatestMigrationVersion(Driver())
+	if err != nil {
+		return err
+	}
+
+	var current uint
+	if err := DB.WithContext(ctx).Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&current).Error; err != nil {
+		return err
+	}
+	if current != latest {
+		return fmt.Errorf("schema is at version %d, expected %d", current, latest)
+	}
+	return nil
+}
+
+// withMigrationLock runs fn while holding the migration lock, on a database
+// with the schema_migrations table. The lock belongs to the session, so it
+// is taken and released on one dedicated connection.
+func withMigrationLock(ctx context.Context, fn func(db *gorm.DB) error) error {
+	var lock, unlock string
+	var args []interface{}
+	switch Driver() {
+	case DriverPostgres:
+		lock, unlock, args = "SELECT pg_advisory_lock($1)", "SELECT pg_advisory_unlock($1)", []interface{}{migrationLockID}
+	case DriverMySQL:
+		lock, unlock, args = "SELECT GET_LOCK(?, -1)", "SELECT RELEASE_LOCK(?)", []interface{}{migrationLockName}
+	}
+
+	if lock != "" {
+		sqlDB, err := DB.DB()
+		if err != nil {
+			return err
+		}
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		// waiting for another instance to migrate may take longer than a statement may
+		if Driver() == DriverPostgres {
+			if _, err := conn.ExecContext(ctx, "SET statement_timeout = 0"); err != nil {
+				return err
+			}
+			defer conn.ExecContext(context.Background(), "RESET statement_timeout")
+		}
+
+		log.Debug().Msg("Waiting for the migration lock")
+		if _, err := conn.ExecContext(ctx, lock, args...); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer func() {
+			// unlock even when the context has been canceled
+			if _, err := conn.ExecContext(context.Background(), unlock, args...); err != nil {
+				log.Error().Err(err).Msg("Failed to release the migration lock")
+			}
+		}()
+	}
+
+	db := DB.WithContext(ctx)
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return err
+	}
+	return fn(db)
+}
+
+func appliedVersions(db *gorm.DB) (map[uint]SchemaMigration, error) {
+	var applied []SchemaMigration
+	if err := db.Order("version").Find(&applied).Error; err != nil {
+		return nil, err
+	}
+
+	versions := make(map[uint]SchemaMigration, len(applied))
+	for _, migration := range applied {
+		versions[migration.Version] = migration
+	}
+	return versions, nil
+}
+
+// sqlMigration returns a step running the statements of an embedded file.
+func sqlMigration(name string) (func(tx *gorm.DB) error, error) {
+	content, err := migrationFiles.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	statements := string(content)
+
+	return func(tx *gorm.DB) error {
+		if strings.TrimSpace(stripSQLComments(statements)) == "" {
+			return nil
+		}
+		return tx.Exec(statements).Error
+	}, nil
+}
+
+// stripSQLComments drops the line comments of a SQL file.
+func stripSQLComments(statements string) string {
+	lines := strings.Split(statements, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// LiftStatementTimeout disables the statement timeout for the rest of the
+// transaction on PostgreSQL, migrations may rewrite large tables and exports
+// stream the whole catalog.
+func LiftStatementTimeout(tx *gorm.DB) error {
+	if Driver() != DriverPostgres {
+		return nil
+	}
+	return tx.Exec("SET LOCAL statement_timeout = 0").Error
+}
+
+// noMigration is the down step of data migrations that cannot be undone.
+func noMigration(*gorm.DB) error {
+	return nil
+}
@@ -0,0 +1,129 @@
+-- Initial schema, the MySQL counterpart of the PostgreSQL schema without
+-- its full-text search and trigram indexes.
+
+CREATE TABLE IF NOT EXISTS `genres` (
+	`id` bigint unsigned AUTO_INCREMENT,
+	`name` varchar(100) NOT NULL,
+	`slug` varchar(100) NOT NULL,
+	`created_at` datetime(3) NULL,
+	`updated_at` datetime(3) NULL,
+	PRIMARY KEY (`id`),
+	UNIQUE INDEX `idx_genres_slug` (`slug`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `movies` (
+	`id` bigint unsigned AUTO_INCREMENT,
+	`title` varchar(255) NOT NULL,
+	`description` text NOT NULL,
+	`poster_url` varchar(255) NOT NULL,
+	`release_date` date NOT NULL,
+	`rating` decimal(3,1) NOT NULL,
+	`duration_minutes` bigint NOT NULL,
+	`director` varchar(255) NOT NULL,
+	`version` bigint unsigned NOT NULL DEFAULT 1,
+	`created_at` datetime(3) NULL,
+	`updated_at` datetime(3) NULL,
+	`deleted_at` datetime(3) NULL,
+	PRIMARY KEY (`id`),
+	INDEX `idx_movies_deleted_at` (`deleted_at`),
+	INDEX `idx_movies_director` (`director`),
+	INDEX `idx_movies_title` (`title`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `movie_genres` (
+	`movie_id` bigint unsigned,
+	`genre_id` bigint unsigned,
+	PRIMARY KEY (`movie_id`, `genre_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `people` (
+	`id` bigint unsigned AUTO_INCREMENT,
+	`name` varchar(255) NOT NULL,
+	`biography` text,
+	`birth_date` date,
+	`photo_url` varchar(255),
+	`created_at` datetime(3) NULL,
+	`updated_at` datetime(3) NULL,
+	PRIMARY KEY (`id`),
+	INDEX `idx_people_name_lower` ((lower(`name`)))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `credits` (
+	`id` bigint unsigned AUTO_INCREMENT,
+	`movie_id` bigint unsigned NOT NULL,
+	`person_id` bigint unsigned NOT NULL,
+	`role` varchar(50) NOT NULL,
+	`character_name` varchar(255),
+	`billing_order` bigint NOT NULL DEFAULT 0,
+	`created_at` datetime(3) NULL,
+	`updated_at` datetime(3) NULL,
+	PRIMARY KEY (`id`),
+	INDEX `idx_credits_person_id` (`person_id`),
+	INDEX `idx_credits_movie_id` (`movie_id`),
+	CONSTRAINT `fk_credits_movie` FOREIGN KEY (`movie_id`) REFERENCES `movies` (`id`) ON DELETE CASCADE,
+	CONSTRAINT `fk_credits_person` FOREIGN KEY (`person_id`) REFERENCES `people` (`id`) ON DELETE RESTRICT
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `movie_revisions` (
+	`id` bigint unsigned AUTO_INCREMENT,
+	`movie_id` bigint unsigned NOT NULL,
+	`revision` bigint unsigned NOT NULL,
+	`action` varchar(20) NOT NULL,
+	`actor` varchar(255) NOT NULL,
+	`request_id` varchar(100),
+	`diff` json NOT NULL,
+	`snapshot` json NOT NULL,
+	`created_at` datetime(3) NULL,
+	PRIMARY KEY (`id`),
+	UNIQUE INDEX `idx_movie_revisions_movie_revision` (`movie_id`, `revision`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `users` (
+	`id` bigint unsigned AUTO_INCREMENT,
+	`email` varchar(255) NOT NULL,
+	`name` varchar(255) NOT NULL,
+	`password_hash` varchar(255) NOT NULL,
+	`role` varchar(20) NOT NULL DEFAULT 'viewer',
+	`created_at` datetime(3) NULL,
+	`updated_at` datetime(3) NULL,
+	PRIMARY KEY (`id`),
+	UNIQUE INDEX `idx_users_email` (`email`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `refresh_tokens` (
+	`id` bigint unsigned AUTO_INCREMENT,
+	`user_id` bigint unsigned NOT NULL,
+	`jti` varchar(64) NOT NULL,
+	`expires_at` datetime(3) NOT NULL,
+	`revoked_at` datetime(3) NULL,
+	`created_at` datetime(3) NULL,
+	PRIMARY KEY (`id`),
+	INDEX `idx_refresh_tokens_expires_at` (`expires_at`),
+	UNIQUE INDEX `idx_refresh_tokens_jti` (`jti`),
+	INDEX `idx_refresh_tokens_user_id` (`user_id`),
+	CONSTRAINT `fk_refresh_tokens_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `revoked_tokens` (
+	`jti` varchar(64),
+	`expires_at` datetime(3) NOT NULL,
+	`created_at` datetime(3) NULL,
+	PRIMARY KEY (`jti`),
+	INDEX `idx_revoked_tokens_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `api_keys` (
+	`id` bigint unsigned AUTO_INCREMENT,
+	`name` varchar(100) NOT NULL,
+	`prefix` varchar(16) NOT NULL,
+	`key_hash` varchar(64) NOT NULL,
+	`scopes` json NOT NULL,
+	`expires_at` datetime(3) NULL,
+	`last_used_at` datetime(3) NULL,
+	`revoked_at` datetime(3) NULL,
+	`created_by_id` bigint unsigned,
+	`created_at` datetime(3) NULL,
+	PRIMARY KEY (`id`),
+	UNIQUE INDEX `idx_api_keys_prefix` (`prefix`),
+	CONSTRAINT `fk_api_keys_created_by` FOREIGN KEY (`created_by_id`) REFERENCES `users` (`id`) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
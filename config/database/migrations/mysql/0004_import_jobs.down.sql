@@ -0,0 +1,3 @@
+-- Bulk movie imports and the report of their rows.
+
+DROP TABLE IF EXISTS `import_jobs`;
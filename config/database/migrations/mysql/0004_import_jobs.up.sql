@@ -0,0 +1,21 @@
+-- Bulk movie imports and the report of their rows.
+
+CREATE TABLE IF NOT EXISTS `import_jobs` (
+	`id` bigint unsigned AUTO_INCREMENT,
+	`status` varchar(20) NOT NULL,
+	`format` varchar(10) NOT NULL,
+	`dry_run` boolean NOT NULL,
+	`upsert` boolean NOT NULL,
+	`actor` varchar(255) NOT NULL,
+	`total` bigint NOT NULL,
+	`processed` bigint NOT NULL,
+	`created` bigint NOT NULL,
+	`updated` bigint NOT NULL,
+	`failed` bigint NOT NULL,
+	`report` json NOT NULL,
+	`error` text,
+	`created_at` datetime(3) NULL,
+	`updated_at` datetime(3) NULL,
+	`finished_at` datetime(3) NULL,
+	PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
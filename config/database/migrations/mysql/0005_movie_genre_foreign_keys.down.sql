@@ -0,0 +1,8 @@
+-- The references of movie_genres to the movies and genres.
+
+ALTER TABLE `movie_genres`
+	DROP FOREIGN KEY `fk_movie_genres_genre`,
+	DROP FOREIGN KEY `fk_movie_genres_movie`;
+
+-- the index MySQL created for the genre constraint
+ALTER TABLE `movie_genres` DROP INDEX `fk_movie_genres_genre`;
@@ -0,0 +1,11 @@
+-- Reference the movies and genres from movie_genres, after dropping the
+-- links to missing rows. Links follow the movies they belong to and keep
+-- their genres from being deleted.
+
+DELETE FROM `movie_genres`
+WHERE `movie_id` NOT IN (SELECT `id` FROM `movies`)
+OR `genre_id` NOT IN (SELECT `id` FROM `genres`);
+
+ALTER TABLE `movie_genres`
+	ADD CONSTRAINT `fk_movie_genres_movie` FOREIGN KEY (`movie_id`) REFERENCES `movies` (`id`) ON DELETE CASCADE,
+	ADD CONSTRAINT `fk_movie_genres_genre` FOREIGN KEY (`genre_id`) REFERENCES `genres` (`id`) ON DELETE RESTRICT;
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS "api_keys";
+DROP TABLE IF EXISTS "revoked_tokens";
+DROP TABLE IF EXISTS "refresh_tokens";
+DROP TABLE IF EXISTS "users";
+DROP TABLE IF EXISTS "movie_revisions";
+DROP TABLE IF EXISTS "credits";
+DROP TABLE IF EXISTS "people";
+DROP TABLE IF EXISTS "movie_genres";
+DROP TABLE IF EXISTS "movies";
+DROP TABLE IF EXISTS "genres";
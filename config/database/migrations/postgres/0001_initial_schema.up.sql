@@ -0,0 +1,146 @@
+-- Schema previously created by GORM AutoMigrate. Every statement is guarded,
+-- so databases migrated that way adopt the versioned migrations as they are.
+-- Movies tables created by an earlier model get the columns they lack before
+-- their indexes, the legacy genre column is moved out by migration 2.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS "genres" (
+	"id" bigserial,
+	"name" varchar(100) NOT NULL,
+	"slug" varchar(100) NOT NULL,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_genres_slug" ON "genres" ("slug");
+
+CREATE TABLE IF NOT EXISTS "movies" (
+	"id" bigserial,
+	"title" varchar(255) NOT NULL,
+	"description" text NOT NULL,
+	"poster_url" varchar(255) NOT NULL,
+	"release_date" date NOT NULL,
+	"rating" decimal(3,1) NOT NULL,
+	"duration_minutes" bigint NOT NULL,
+	"director" varchar(255) NOT NULL,
+	"version" bigint NOT NULL DEFAULT 1,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"search_vector" tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(director, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(description, '')), 'C')
+	) STORED,
+	PRIMARY KEY ("id")
+);
+ALTER TABLE "movies" ADD COLUMN IF NOT EXISTS "version" bigint NOT NULL DEFAULT 1;
+ALTER TABLE "movies" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "movies" ADD COLUMN IF NOT EXISTS "search_vector" tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+	setweight(to_tsvector('english', coalesce(director, '')), 'B') ||
+	setweight(to_tsvector('english', coalesce(description, '')), 'C')
+) STORED;
+CREATE INDEX IF NOT EXISTS "idx_movies_search_vector" ON "movies" USING gin ("search_vector");
+CREATE INDEX IF NOT EXISTS "idx_movies_deleted_at" ON "movies" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_movies_director_trgm" ON "movies" USING gin (director gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS "idx_movies_title_trgm" ON "movies" USING gin (title gin_trgm_ops);
+
+CREATE TABLE IF NOT EXISTS "movie_genres" (
+	"movie_id" bigint,
+	"genre_id" bigint,
+	PRIMARY KEY ("movie_id", "genre_id")
+);
+
+CREATE TABLE IF NOT EXISTS "people" (
+	"id" bigserial,
+	"name" varchar(255) NOT NULL,
+	"biography" text,
+	"birth_date" date,
+	"photo_url" varchar(255),
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_people_name_lower" ON "people" (lower(name));
+
+CREATE TABLE IF NOT EXISTS "credits" (
+	"id" bigserial,
+	"movie_id" bigint NOT NULL,
+	"person_id" bigint NOT NULL,
+	"role" varchar(50) NOT NULL,
+	"character_name" varchar(255),
+	"billing_order" bigint NOT NULL DEFAULT 0,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_credits_movie" FOREIGN KEY ("movie_id") REFERENCES "movies" ("id") ON DELETE CASCADE,
+	CONSTRAINT "fk_credits_person" FOREIGN KEY ("person_id") REFERENCES "people" ("id") ON DELETE RESTRICT
+);
+CREATE INDEX IF NOT EXISTS "idx_credits_person_id" ON "credits" ("person_id");
+CREATE INDEX IF NOT EXISTS "idx_credits_movie_id" ON "credits" ("movie_id");
+
+CREATE TABLE IF NOT EXISTS "movie_revisions" (
+	"id" bigserial,
+	"movie_id" bigint NOT NULL,
+	"revision" bigint NOT NULL,
+	"action" varchar(20) NOT NULL,
+	"actor" varchar(255) NOT NULL,
+	"request_id" varchar(100),
+	"diff" jsonb NOT NULL,
+	"snapshot" jsonb NOT NULL,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_movie_revisions_movie_revision" ON "movie_revisions" ("movie_id", "revision");
+
+CREATE TABLE IF NOT EXISTS "users" (
+	"id" bigserial,
+	"email" varchar(255) NOT NULL,
+	"name" varchar(255) NOT NULL,
+	"password_hash" varchar(255) NOT NULL,
+	"role" varchar(20) NOT NULL DEFAULT 'viewer',
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+
+CREATE TABLE IF NOT EXISTS "refresh_tokens" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"jti" varchar(64) NOT NULL,
+	"expires_at" timestamptz NOT NULL,
+	"revoked_at" timestamptz,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_refresh_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_expires_at" ON "refresh_tokens" ("expires_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_refresh_tokens_jti" ON "refresh_tokens" ("jti");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_user_id" ON "refresh_tokens" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "revoked_tokens" (
+	"jti" varchar(64),
+	"expires_at" timestamptz NOT NULL,
+	"created_at" timestamptz,
+	PRIMARY KEY ("jti")
+);
+CREATE INDEX IF NOT EXISTS "idx_revoked_tokens_expires_at" ON "revoked_tokens" ("expires_at");
+
+CREATE TABLE IF NOT EXISTS "api_keys" (
+	"id" bigserial,
+	"name" varchar(100) NOT NULL,
+	"prefix" varchar(16) NOT NULL,
+	"key_hash" varchar(64) NOT NULL,
+	"scopes" jsonb NOT NULL,
+	"expires_at" timestamptz,
+	"last_used_at" timestamptz,
+	"revoked_at" timestamptz,
+	"created_by_id" bigint,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_api_keys_created_by" FOREIGN KEY ("created_by_id") REFERENCES "users" ("id") ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_prefix" ON "api_keys" ("prefix");
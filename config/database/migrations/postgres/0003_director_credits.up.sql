@@ -0,0 +1,18 @@
+-- Turn the movies.director strings into people rows and director credits,
+-- skipping movies that already have a director credit.
+
+INSERT INTO people (name, created_at, updated_at)
+SELECT DISTINCT ON (LOWER(TRIM(movies.director))) TRIM(movies.director), NOW(), NOW()
+FROM movies
+WHERE TRIM(movies.director) <> ''
+AND NOT EXISTS (SELECT 1 FROM people WHERE LOWER(people.name) = LOWER(TRIM(movies.director)));
+
+INSERT INTO credits (movie_id, person_id, role, billing_order, created_at, updated_at)
+SELECT movies.id, (
+	SELECT people.id FROM people
+	WHERE LOWER(people.name) = LOWER(TRIM(movies.director))
+	ORDER BY people.id LIMIT 1
+), 'director', 0, NOW(), NOW()
+FROM movies
+WHERE TRIM(movies.director) <> ''
+AND NOT EXISTS (SELECT 1 FROM credits WHERE credits.movie_id = movies.id AND credits.role = 'director');
@@ -0,0 +1,21 @@
+-- Bulk movie imports and the report of their rows.
+
+CREATE TABLE IF NOT EXISTS "import_jobs" (
+	"id" bigserial,
+	"status" varchar(20) NOT NULL,
+	"format" varchar(10) NOT NULL,
+	"dry_run" boolean NOT NULL,
+	"upsert" boolean NOT NULL,
+	"actor" varchar(255) NOT NULL,
+	"total" bigint NOT NULL,
+	"processed" bigint NOT NULL,
+	"created" bigint NOT NULL,
+	"updated" bigint NOT NULL,
+	"failed" bigint NOT NULL,
+	"report" jsonb NOT NULL,
+	"error" text,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"finished_at" timestamptz,
+	PRIMARY KEY ("id")
+);
@@ -0,0 +1,4 @@
+-- The references of movie_genres to the movies and genres.
+
+ALTER TABLE "movie_genres" DROP CONSTRAINT IF EXISTS "fk_movie_genres_genre";
+ALTER TABLE "movie_genres" DROP CONSTRAINT IF EXISTS "fk_movie_genres_movie";
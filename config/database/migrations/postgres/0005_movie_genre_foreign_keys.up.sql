@@ -0,0 +1,15 @@
+-- Reference the movies and genres from movie_genres like GORM AutoMigrate
+-- does, after dropping the links to missing rows. Links follow the movies
+-- they belong to and keep their genres from being deleted.
+
+DELETE FROM "movie_genres"
+WHERE "movie_id" NOT IN (SELECT "id" FROM "movies")
+OR "genre_id" NOT IN (SELECT "id" FROM "genres");
+
+-- databases auto-migrated in development have the constraints already
+ALTER TABLE "movie_genres" DROP CONSTRAINT IF EXISTS "fk_movie_genres_movie";
+ALTER TABLE "movie_genres" DROP CONSTRAINT IF EXISTS "fk_movie_genres_genre";
+
+ALTER TABLE "movie_genres"
+	ADD CONSTRAINT "fk_movie_genres_movie" FOREIGN KEY ("movie_id") REFERENCES "movies" ("id") ON DELETE CASCADE,
+	ADD CONSTRAINT "fk_movie_genres_genre" FOREIGN KEY ("genre_id") REFERENCES "genres" ("id") ON DELETE RESTRICT;
@@ -0,0 +1,120 @@
+-- Initial schema, the SQLite counterpart of the PostgreSQL schema without
+-- its full-text search and trigram indexes.
+
+CREATE TABLE IF NOT EXISTS `genres` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`name` varchar(100) NOT NULL,
+	`slug` varchar(100) NOT NULL,
+	`created_at` datetime,
+	`updated_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_genres_slug` ON `genres` (`slug`);
+
+CREATE TABLE IF NOT EXISTS `movies` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`title` varchar(255) NOT NULL,
+	`description` text NOT NULL,
+	`poster_url` varchar(255) NOT NULL,
+	`release_date` date NOT NULL,
+	`rating` decimal(3,1) NOT NULL,
+	`duration_minutes` integer NOT NULL,
+	`director` varchar(255) NOT NULL,
+	`version` integer NOT NULL DEFAULT 1,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_movies_deleted_at` ON `movies` (`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_movies_director` ON `movies` (`director`);
+CREATE INDEX IF NOT EXISTS `idx_movies_title` ON `movies` (`title`);
+
+CREATE TABLE IF NOT EXISTS `movie_genres` (
+	`movie_id` integer,
+	`genre_id` integer,
+	PRIMARY KEY (`movie_id`, `genre_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `people` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`name` varchar(255) NOT NULL,
+	`biography` text,
+	`birth_date` date,
+	`photo_url` varchar(255),
+	`created_at` datetime,
+	`updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_people_name_lower` ON `people` (lower(`name`));
+
+CREATE TABLE IF NOT EXISTS `credits` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`movie_id` integer NOT NULL,
+	`person_id` integer NOT NULL,
+	`role` varchar(50) NOT NULL,
+	`character_name` varchar(255),
+	`billing_order` integer NOT NULL DEFAULT 0,
+	`created_at` datetime,
+	`updated_at` datetime,
+	CONSTRAINT `fk_credits_movie` FOREIGN KEY (`movie_id`) REFERENCES `movies` (`id`) ON DELETE CASCADE,
+	CONSTRAINT `fk_credits_person` FOREIGN KEY (`person_id`) REFERENCES `people` (`id`) ON DELETE RESTRICT
+);
+CREATE INDEX IF NOT EXISTS `idx_credits_person_id` ON `credits` (`person_id`);
+CREATE INDEX IF NOT EXISTS `idx_credits_movie_id` ON `credits` (`movie_id`);
+
+CREATE TABLE IF NOT EXISTS `movie_revisions` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`movie_id` integer NOT NULL,
+	`revision` integer NOT NULL,
+	`action` varchar(20) NOT NULL,
+	`actor` varchar(255) NOT NULL,
+	`request_id` varchar(100),
+	`diff` json NOT NULL,
+	`snapshot` json NOT NULL,
+	`created_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_movie_revisions_movie_revision` ON `movie_revisions` (`movie_id`, `revision`);
+
+CREATE TABLE IF NOT EXISTS `users` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`email` varchar(255) NOT NULL,
+	`name` varchar(255) NOT NULL,
+	`password_hash` varchar(255) NOT NULL,
+	`role` varchar(20) NOT NULL DEFAULT 'viewer',
+	`created_at` datetime,
+	`updated_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_users_email` ON `users` (`email`);
+
+CREATE TABLE IF NOT EXISTS `refresh_tokens` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`user_id` integer NOT NULL,
+	`jti` varchar(64) NOT NULL,
+	`expires_at` datetime NOT NULL,
+	`revoked_at` datetime,
+	`created_at` datetime,
+	CONSTRAINT `fk_refresh_tokens_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS `idx_refresh_tokens_expires_at` ON `refresh_tokens` (`expires_at`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_refresh_tokens_jti` ON `refresh_tokens` (`jti`);
+CREATE INDEX IF NOT EXISTS `idx_refresh_tokens_user_id` ON `refresh_tokens` (`user_id`);
+
+CREATE TABLE IF NOT EXISTS `revoked_tokens` (
+	`jti` varchar(64) PRIMARY KEY,
+	`expires_at` datetime NOT NULL,
+	`created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_revoked_tokens_expires_at` ON `revoked_tokens` (`expires_at`);
+
+CREATE TABLE IF NOT EXISTS `api_keys` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`name` varchar(100) NOT NULL,
+	`prefix` varchar(16) NOT NULL,
+	`key_hash` varchar(64) NOT NULL,
+	`scopes` json NOT NULL,
+	`expires_at` datetime,
+	`last_used_at` datetime,
+	`revoked_at` datetime,
+	`created_by_id` integer,
+	`created_at` datetime,
+	CONSTRAINT `fk_api_keys_created_by` FOREIGN KEY (`created_by_id`) REFERENCES `users` (`id`) ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_api_keys_prefix` ON `api_keys` (`prefix`);
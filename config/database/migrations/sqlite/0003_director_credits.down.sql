@@ -0,0 +1 @@
+-- Director credits cannot be told apart from the ones added later, they are kept.
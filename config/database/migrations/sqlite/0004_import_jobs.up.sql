@@ -0,0 +1,20 @@
+-- Bulk movie imports and the report of their rows.
+
+CREATE TABLE IF NOT EXISTS `import_jobs` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`status` varchar(20) NOT NULL,
+	`format` varchar(10) NOT NULL,
+	`dry_run` numeric NOT NULL,
+	`upsert` numeric NOT NULL,
+	`actor` varchar(255) NOT NULL,
+	`total` integer NOT NULL,
+	`processed` integer NOT NULL,
+	`created` integer NOT NULL,
+	`updated` integer NOT NULL,
+	`failed` integer NOT NULL,
+	`report` json NOT NULL,
+	`error` text,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`finished_at` datetime
+);
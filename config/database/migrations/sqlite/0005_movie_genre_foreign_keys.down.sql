@@ -0,0 +1,10 @@
+-- The references of movie_genres to the movies and genres.
+
+CREATE TABLE `movie_genres_old` (
+	`movie_id` integer,
+	`genre_id` integer,
+	PRIMARY KEY (`movie_id`, `genre_id`)
+);
+INSERT INTO `movie_genres_old` (`movie_id`, `genre_id`) SELECT `movie_id`, `genre_id` FROM `movie_genres`;
+DROP TABLE `movie_genres`;
+ALTER TABLE `movie_genres_old` RENAME TO `movie_genres`;
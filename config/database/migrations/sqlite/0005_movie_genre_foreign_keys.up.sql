@@ -0,0 +1,19 @@
+-- Reference the movies and genres from movie_genres, after dropping the
+-- links to missing rows. Links follow the movies they belong to and keep
+-- their genres from being deleted. SQLite cannot add constraints to a
+-- table, it is rebuilt.
+
+DELETE FROM `movie_genres`
+WHERE `movie_id` NOT IN (SELECT `id` FROM `movies`)
+OR `genre_id` NOT IN (SELECT `id` FROM `genres`);
+
+CREATE TABLE `movie_genres_new` (
+	`movie_id` integer,
+	`genre_id` integer,
+	PRIMARY KEY (`movie_id`, `genre_id`),
+	CONSTRAINT `fk_movie_genres_movie` FOREIGN KEY (`movie_id`) REFERENCES `movies` (`id`) ON DELETE CASCADE,
+	CONSTRAINT `fk_movie_genres_genre` FOREIGN KEY (`genre_id`) REFERENCES `genres` (`id`) ON DELETE RESTRICT
+);
+INSERT INTO `movie_genres_new` (`movie_id`, `genre_id`) SELECT `movie_id`, `genre_id` FROM `movie_genres`;
+DROP TABLE `movie_genres`;
+ALTER TABLE `movie_genres_new` RENAME TO `movie_genres`;
@@ -0,0 +1,205 @@
+package database
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestMigrations(t *testing.T) {
+	for _, driver := range Drivers {
+		migrations, err := Migrations(driver)
+		if err != nil {
+			t.Fatalf("Migrations(%s): %v", driver, err)
+		}
+
+		// every driver has the same versions, in order and without gaps
+		for i, migration := range migrations {
+			if migration.Version != uint(i+1) {
+				t.Errorf("%s migration %d_%s, want version %d", driver, migration.Version, migration.Name, i+1)
+			}
+		}
+		latest, err := LatestMigrationVersion(driver)
+		if err != nil || latest != uint(len(migrations)) {
+			t.Errorf("LatestMigrationVersion(%s) = %d, %v, want %d", driver, latest, err, len(migrations))
+		}
+	}
+}
+
+func TestMigrateSQLite(t *testing.T) {
+	useTestDB(t, openTestDB(t, DriverSQLite, "file::memory:?_pragma=foreign_keys(1)"))
+	ctx := context.Background()
+
+	migrations, err := Migrations(DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied, err := MigrateUp(ctx); err != nil || applied != len(migrations) {
+		t.Fatalf("MigrateUp = %d, %v, want %d", applied, err, len(migrations))
+	}
+	if err := CheckMigrations(ctx); err != nil {
+		t.Fatalf("CheckMigrations after up: %v", err)
+	}
+
+	// genres are only linked to movies that exist
+	if err := DB.Exec("INSERT INTO movie_genres (movie_id, genre_id) VALUES (1, 1)").Error; err == nil {
+		t.Error("movie_genres links a missing movie and genre")
+	}
+
+	// the down steps revert every table and the schema can be migrated again
+	if reverted, err := MigrateDown(ctx, len(migrations)); err != nil || reverted != len(migrations) {
+		t.Fatalf("MigrateDown = %d, %v, want %d", reverted, err, len(migrations))
+	}
+	for _, table := range []string{"movies", "genres", "credits", "users", "import_jobs"} {
+		if DB.Migrator().HasTable(table) {
+			t.Errorf("table %s is left after migrating down", table)
+		}
+	}
+	if _, err := MigrateUp(ctx); err != nil {
+		t.Fatalf("MigrateUp after down: %v", err)
+	}
+}
+
+// baselineMovie is the movie model of the first release, whose table was
+// created by GORM AutoMigrate before the versioned migrations.
+type baselineMovie struct {
+	ID              uint           `gorm:"primaryKey;autoIncrement"`
+	Title           string         `gorm:"type:varchar(255);not null"`
+	Description     string         `gorm:"type:text;not null"`
+	PosterURL       string         `gorm:"type:varchar(255);not null"`
+	ReleaseDate     string         `gorm:"type:date;not null"`
+	Rating          float64        `gorm:"type:decimal(3,1);not null"`
+	DurationMinutes int            `gorm:"type:int;not null"`
+	Director        string         `gorm:"type:varchar(255);not null"`
+	Genre           datatypes.JSON `gorm:"type:json;not null"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime"`
+}
+
+func (baselineMovie) TableName() string {
+	return "movies"
+}
+
+// TestMigrateUpFromBaseline migrates a PostgreSQL database created by the
+// first release, in a schema of its own on the server of DATABASE_URL. It is
+// skipped when the variable is not set.
+func TestMigrateUpFromBaseline(t *testing.T) {
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	admin := openTestDB(t, DriverPostgres, dsn)
+	schema := "migrate_baseline_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	if strings.Contains(dsn, "://") {
+		dsn = withQueryParam(dsn, "search_path", schema+",public")
+	} else {
+		dsn += " search_path=" + schema + ",public"
+	}
+	db := openTestDB(t, DriverPostgres, dsn)
+	useTestDB(t, db)
+
+	// the first release created the movies table with a genre JSON column
+	if err := db.AutoMigrate(&baselineMovie{}); err != nil {
+		t.Fatal(err)
+	}
+	movie := &baselineMovie{
+		Title:           "Alien",
+		Description:     "A crew meets a deadly creature",
+		PosterURL:       "https://example.com/alien.jpg",
+		ReleaseDate:     "1979-05-25",
+		Rating:          8.5,
+		DurationMinutes: 117,
+		Director:        "Ridley Scott",
+		Genre:           datatypes.JSON(`["Horror", "sci-fi"]`),
+	}
+	if err := db.Create(movie).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := Migrations(DriverPostgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied, err := MigrateUp(ctx); err != nil || applied != len(migrations) {
+		t.Fatalf("MigrateUp = %d, %v, want %d", applied, err, len(migrations))
+	}
+	if err := CheckMigrations(ctx); err != nil {
+		t.Fatalf("CheckMigrations: %v", err)
+	}
+
+	// the columns of the current model are added and the genres moved out
+	for _, column := range []string{"version", "deleted_at", "search_vector"} {
+		if !db.Migrator().HasColumn("movies", column) {
+			t.Errorf("movies.%s is missing", column)
+		}
+	}
+	if db.Migrator().HasColumn("movies", "genre") {
+		t.Error("movies.genre is left")
+	}
+
+	var row struct {
+		Version  uint
+		Searched bool
+	}
+	err = db.Raw("SELECT version, search_vector @@ plainto_tsquery('english', 'creature') AS searched FROM movies WHERE id = ?", movie.ID).Scan(&row).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.Version != 1 || !row.Searched {
+		t.Errorf("migrated movie = version %d searched %t, want version 1 found by its description", row.Version, row.Searched)
+	}
+
+	var slugs []string
+	err = db.Raw("SELECT genres.slug FROM movie_genres JOIN genres ON genres.id = movie_genres.genre_id WHERE movie_genres.movie_id = ? ORDER BY genres.slug", movie.ID).
+		Scan(&slugs).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(slugs, ",") != "horror,science-fiction" {
+		t.Errorf("migrated genres = %v, want horror and science-fiction", slugs)
+	}
+}
+
+// openTestDB opens a database of the driver without a statement timeout.
+func openTestDB(t *testing.T, driver, dsn string) *gorm.DB {
+	t.Helper()
+	dialector, err := dialector(driver, dsn, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("open %s: %v", driver, err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection to an in-memory SQLite database opens a new one
+	if driver == DriverSQLite {
+		sqlDB.SetMaxOpenConns(1)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// useTestDB makes db the database of the package for the rest of the test.
+func useTestDB(t *testing.T, db *gorm.DB) {
+	previous := DB
+	DB = db
+	t.Cleanup(func() { DB = previous })
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// replicaSetting marks the queries allowed to read from a replica. GORM
+// passes settings on to preloads, unlike clauses.
+const replicaSetting = "movie_app:replica"
+
+// ReadFromReplica lets the reads of db, preloads included, go to a read
+// replica. Reads stay on the primary otherwise, replicas may lag behind.
+// Transactions always run on the primary.
+func ReadFromReplica(db *gorm.DB) *gorm.DB {
+	return db.Set(replicaSetting, true).Session(&gorm.Session{})
+}
+
+// useReplicas routes the reads that opted in to the configured replicas,
+// picked at random, with the pool settings of the primary.
+func useReplicas(db *gorm.DB, config *config.Config) error {
+	if len(config.DBReplicas) == 0 {
+		return nil
+	}
+	if config.DBDriver == DriverSQLite {
+		log.Warn().Msg("SQLite has no read replicas, DB_REPLICAS is ignored")
+		return nil
+	}
+
+	replicas := make([]gorm.Dialector, 0, len(config.DBReplicas))
+	for _, dsn := range config.DBReplicas {
+		replica, err := dialector(config.DBDriver, dsn, config.DBStatementTimeout)
+		if err != nil {
+			return err
+		}
+		replicas = append(replicas, replica)
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{Replicas: replicas, Policy: dbresolver.RandomPolicy{}}).
+		SetMaxOpenConns(config.DBMaxOpenConns).
+		SetMaxIdleConns(config.DBMaxIdleConns).
+		SetConnMaxLifetime(config.DBConnMaxLifetime).
+		SetConnMaxIdleTime(config.DBConnMaxIdleTime)
+	if err := db.Use(resolver); err != nil {
+		return err
+	}
+
+	// dbresolver sends every read to the replicas, keep the others on the primary
+	if err := db.Callback().Query().After("gorm:db_resolver").Before("gorm:query").Register("app:primary_reads", primaryReads); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().After("gorm:db_resolver").Before("gorm:row").Register("app:primary_reads", primaryReads); err != nil {
+		return err
+	}
+	if err := db.Callback().Raw().After("gorm:db_resolver").Before("gorm:raw").Register("app:primary_reads", primaryReads); err != nil {
+		return err
+	}
+
+	log.Info().Int("replicas", len(replicas)).Msg("Database read replicas configured")
+	return nil
+}
+
+// primaryReads sends the reads to the primary unless they opted in to the
+// replicas with ReadFromReplica.
+func primaryReads(db *gorm.DB) {
+	if _, ok := db.Get(replicaSetting); !ok {
+		dbresolver.Write.ModifyStatement(db.Statement)
+	}
+}
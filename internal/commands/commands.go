@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+)
+
+// Run runs the command named by the first argument, for instance
+// "migrate up", instead of starting the server.
+func Run(config *config.Config, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return Migrate(config, args[1:])
+	case "seed":
+		return Seed(config, args[1:])
+	case "user":
+		return User(config, args[1:])
+	default:
+		return fmt.Errorf("unknown command %q, expected migrate, seed or user", args[0])
+	}
+}
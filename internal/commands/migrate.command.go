@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+	"github.com/zdacoder/go-fiber-movie-app-api/config/database"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/shutdown"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status | create <name>"
+
+// Migrate manages the versioned database migrations:
+//
+//	migrate up             apply every pending migration
+//	migrate down [steps]   revert the latest migrations, one by default
+//	migrate status         list the migrations and when they were applied
+//	migrate create <name>  create an empty migration for every driver in config/database/migrations
+func Migrate(config *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	// creating files does not need the database
+	if args[0] == "create" {
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		files, err := database.CreateMigration(database.MigrationsDir, strings.Join(args[1:], " "))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			fmt.Printf("Created %s\n", file)
+		}
+		return nil
+	}
+
+	database.Connect(config)
+	defer shutdown.Run(context.Background())
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			parsed, err := strconv.Atoi(args[1])
+			if err != nil || parsed < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+			steps = parsed
+		}
+		reverted, err := database.MigrateDown(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reverted %d migration(s)\n", reverted)
+	case "status":
+		return printMigrationStatus(ctx)
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
+
+func printMigrationStatus(ctx context.Context) error {
+	statuses, err := database.MigrationStatuses(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+	for _, status := range statuses {
+		state := "pending"
+		switch {
+		case status.Unknown:
+			state = "applied " + status.AppliedAt.Format(time.RFC3339) + ", unknown to this binary"
+		case status.AppliedAt != nil:
+			state = "applied " + status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, state)
+	}
+	return w.Flush()
+}
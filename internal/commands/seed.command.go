@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+	"github.com/zdacoder/go-fiber-movie-app-api/config/database"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/seeds"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/shutdown"
+)
+
+// Seed fills the database with sample data, on a migrated schema:
+//
+//	seed           load the curated genres, people and movies, skipping the ones present
+//	seed --fake N  create N randomized movies as well as the curated genres
+func Seed(config *config.Config, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	fake := flags.Int("fake", 0, "number of randomized movies to create instead of the curated fixtures")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *fake < 0 {
+		return fmt.Errorf("invalid number of fake movies %d", *fake)
+	}
+
+	database.Connect(config)
+	defer shutdown.Run(context.Background())
+
+	// fixtures are validated like API payloads
+	validators.Init()
+
+	ctx := context.Background()
+	if err := database.CheckMigrations(ctx); err != nil {
+		return fmt.Errorf("database is not migrated, run migrate up first: %w", err)
+	}
+
+	var result *seeds.Result
+	var err error
+	if *fake > 0 {
+		result, err = seeds.Fake(ctx, database.DB, *fake)
+	} else {
+		result, err = seeds.Seed(ctx, database.DB)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created %d genre(s), %d people, %d movie(s) and %d credit(s), skipped %d existing record(s)\n",
+		result.Genres, result.People, result.Movies, result.Credits, result.Skipped)
+	return nil
+}
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+	"github.com/zdacoder/go-fiber-movie-app-api/config/database"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/shutdown"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// User manages user accounts, on a migrated schema. Registration through the
+// API only creates viewers, the first admin is created here:
+//
+//	user create --email E --name N [--role R | --admin]  create an account, reading its password from the first line of standard input
+func User(config *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "create" {
+		return errors.New("expected user create")
+	}
+
+	flags := flag.NewFlagSet("user create", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the account")
+	name := flags.String("name", "", "name of the account")
+	role := flags.String("role", models.UserRoleViewer, "role of the account, viewer, editor or admin")
+	admin := flags.Bool("admin", false, "create an admin, like --role admin")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *admin {
+		*role = models.UserRoleAdmin
+	}
+
+	// the password stays out of the shell history and the process list
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	fmt.Fprintln(os.Stderr)
+
+	// the account is validated like a registration
+	validators.Init()
+	req := &models.RegisterRequest{Email: *email, Name: *name, Password: strings.TrimRight(password, "\r\n")}
+	if errs := validators.ValidateStruct(req); errs != nil {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	if errs := validators.ValidateStruct(&models.UserRoleRequest{Role: *role}); errs != nil {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	database.Connect(config)
+	defer shutdown.Run(context.Background())
+
+	ctx := context.Background()
+	if err := database.CheckMigrations(ctx); err != nil {
+		return fmt.Errorf("database is not migrated, run migrate up first: %w", err)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user := &models.User{
+		Email:        strings.ToLower(strings.TrimSpace(req.Email)),
+		Name:         strings.TrimSpace(req.Name),
+		PasswordHash: string(hash),
+		Role:         *role,
+	}
+
+	users := repositories.NewUserRepository(database.DB)
+	taken, err := users.EmailTaken(ctx, user.Email)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("email %s is already registered", user.Email)
+	}
+	if err := users.Create(ctx, user); err != nil {
+		return err
+	}
+
+	fmt.Printf("Created %s %s with ID %d\n", user.Role, user.Email, user.ID)
+	return nil
+}
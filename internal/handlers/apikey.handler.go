@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/middlewares"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/apikey"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+	"gorm.io/datatypes"
+)
+
+// ListAPIKeys godoc
+// @Summary      List API keys
+// @Description  get a paginated list of API keys, newest first, without the keys themselves
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        page      query     int     false  "Page number"  minimum(1)
+// @Param        per_page  query     int     false  "API keys per page"  minimum(1)  maximum(100)
+// @Success      200  {object}  utils.SuccessResponse{data=[]models.APIKey,meta=utils.Pagination} "API keys fetched successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid query parameters"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to fetch API keys"
+// @Router       /api/api-keys [get]
+func (h *Handler) ListAPIKeys(ctx *fiber.Ctx) error {
+	// parse the query parameters
+	query := new(queries.PageQuery)
+	if err := ctx.QueryParser(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid query parameters", err.Error())
+	}
+
+	// validate the query parameters
+	if err := validators.ValidateStruct(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// fetch the requested page of API keys and count the API keys
+	keys, total, err := h.APIKeys.List(ctx.UserContext(), query)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch API keys", err.Error())
+	}
+
+	// return success response with API keys data and pagination metadata
+	pagination := utils.NewPagination(ctx, query.CurrentPage(), query.Size(), total)
+	return utils.PaginatedResponse(ctx, "API keys fetched successfully", keys, pagination)
+}
+
+// CreateAPIKey godoc
+// @Summary      Create an API key
+// @Description  create a scoped API key for a server-to-server integration, the key is only returned in this response
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        key  body      models.APIKeyRequest  true  "API key data"
+// @Success      201  {object}  utils.SuccessResponse{data=models.CreatedAPIKey} "API key created successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid request body or validation failed"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to create API key"
+// @Router       /api/api-keys [post]
+func (h *Handler) CreateAPIKey(ctx *fiber.Ctx) error {
+	// parse the request body
+	req := new(models.APIKeyRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid request body", err.Error())
+	}
+
+	// validate the API key data
+	if err := validators.ValidateStruct(req); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return utils.BadRequestResponse(ctx, "Validation failed", []string{"ExpiresAt: Value must be in the future"})
+	}
+
+	// generate the key, only its hash is stored
+	plain, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to create API key", err.Error())
+	}
+
+	key := models.CreatedAPIKey{
+		APIKey: models.APIKey{
+			Name:      req.Name,
+			Prefix:    prefix,
+			KeyHash:   hash,
+			Scopes:    datatypes.NewJSONSlice(req.Scopes),
+			ExpiresAt: req.ExpiresAt,
+		},
+		Key: plain,
+	}
+	if claims := middlewares.CurrentUser(ctx); claims != nil {
+		userID := claims.UserID()
+		key.CreatedByID = &userID
+	}
+
+	// create the API key record
+	if err := h.APIKeys.Create(ctx.UserContext(), &key.APIKey); err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to create API key", err.Error())
+	}
+
+	// return success response with the key
+	return utils.CreatedResponse(ctx, "API key created successfully", key)
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke an API key
+// @Description  revoke an API key by ID, requests using it are rejected from then on
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "API key ID"
+// @Success      200  {object}  utils.SuccessResponse{data=models.APIKey} "API key revoked successfully"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      404  {object}  utils.ErrorResponse "API key not found"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to revoke API key"
+// @Router       /api/api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey(ctx *fiber.Ctx) error {
+	// fetch the API key by ID
+	key, err := h.APIKeys.Get(ctx.UserContext(), paramID(ctx, "id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "API key not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to revoke API key", err.Error())
+	}
+
+	// revoking twice keeps the original revocation time
+	if err := h.APIKeys.Revoke(ctx.UserContext(), key); err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to revoke API key", err.Error())
+	}
+
+	// return success response with API key data
+	return utils.OKResponse(ctx, "API key revoked successfully", key)
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/middlewares"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/token"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var errRefreshTokenUsed = errors.New("the refresh token has already been used")
+
+// Register godoc
+// @Summary      Register a user
+// @Description  create a viewer account and log it in, admins are created with the user create command and promote other users
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        user  body      models.RegisterRequest  true  "Account data"
+// @Success      201  {object}  utils.SuccessResponse{data=models.AuthSession} "User registered successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid request body or validation failed"
+// @Failure      409  {object}  utils.ErrorResponse "Email is already registered"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to register user"
+// @Router       /api/auth/register [post]
+func (h *Handler) Register(ctx *fiber.Ctx) error {
+	// parse the request body
+	req := new(models.RegisterRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid request body", err.Error())
+	}
+
+	// validate the account data
+	if err := validators.ValidateStruct(req); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// emails are unique regardless of case
+	user := &models.User{
+		Email: strings.ToLower(strings.TrimSpace(req.Email)),
+		Name:  strings.TrimSpace(req.Name),
+	}
+
+	// make sure the email is not registered yet
+	taken, err := h.Users.EmailTaken(ctx.UserContext(), user.Email)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to register user", err.Error())
+	}
+	if taken {
+		return utils.ConflictResponse(ctx, "Email is already registered", user.Email)
+	}
+
+	// hash the password
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to register user", err.Error())
+	}
+	user.PasswordHash = string(hash)
+
+	// create the viewer and its tokens
+	user.Role = models.UserRoleViewer
+	var tokens *token.Pair
+	err = h.Tx.WithinTransaction(ctx.UserContext(), func(tx context.Context) error {
+		if err := h.Users.Create(tx, user); err != nil {
+			return err
+		}
+		issued, err := h.issueTokens(tx, user)
+		tokens = issued
+		return err
+	})
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to register user", err.Error())
+	}
+
+	// return success response with the user and its tokens
+	return utils.CreatedResponse(ctx, "User registered successfully", models.AuthSession{User: user, Tokens: tokens})
+}
+
+// Login godoc
+// @Summary      Log in
+// @Description  exchange an email and password for an access and a refresh token
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        credentials  body      models.LoginRequest  true  "Credentials"
+// @Success      200  {object}  utils.SuccessResponse{data=models.AuthSession} "Logged in successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid request body or validation failed"
+// @Failure      401  {object}  utils.ErrorResponse "Invalid email or password"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to log in"
+// @Router       /api/auth/login [post]
+func (h *Handler) Login(ctx *fiber.Ctx) error {
+	// parse the request body
+	req := new(models.LoginRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid request body", err.Error())
+	}
+
+	// validate the credentials
+	if err := validators.ValidateStruct(req); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// fetch the user and check the password, without telling which one is wrong
+	user, err := h.Users.GetByEmail(ctx.UserContext(), strings.ToLower(strings.TrimSpace(req.Email)))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.UnauthorizedResponse(ctx, "Invalid email or password", nil)
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to log in", err.Error())
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)) != nil {
+		return utils.UnauthorizedResponse(ctx, "Invalid email or password", nil)
+	}
+
+	// issue a new token pair
+	tokens, err := h.issueTokens(ctx.UserContext(), user)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to log in", err.Error())
+	}
+
+	// return success response with the user and its tokens
+	return utils.OKResponse(ctx, "Logged in successfully", models.AuthSession{User: user, Tokens: tokens})
+}
+
+// RefreshTokens godoc
+// @Summary      Refresh tokens
+// @Description  rotate a refresh token into a new access and refresh token, a refresh token can only be used once and reusing it revokes every session of the user
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        token  body      models.RefreshRequest  true  "Refresh token"
+// @Success      200  {object}  utils.SuccessResponse{data=models.AuthSession} "Tokens refreshed successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid request body or validation failed"
+// @Failure      401  {object}  utils.ErrorResponse "Invalid refresh token"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to refresh tokens"
+// @Router       /api/auth/refresh [post]
+func (h *Handler) RefreshTokens(ctx *fiber.Ctx) error {
+	// parse the request body
+	req := new(models.RefreshRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid request body", err.Error())
+	}
+
+	// validate the request
+	if err := validators.ValidateStruct(req); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// verify the refresh token
+	claims, err := token.Parse(req.RefreshToken, token.TypeRefresh)
+	if err != nil {
+		return utils.UnauthorizedResponse(ctx, "Invalid refresh token", err.Error())
+	}
+
+	// fetch the stored refresh token
+	stored, err := h.Tokens.GetRefresh(ctx.UserContext(), claims.ID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.UnauthorizedResponse(ctx, "Invalid refresh token", token.ErrInvalidToken.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to refresh tokens", err.Error())
+	}
+
+	// a rotated token presented again may have been stolen, end every session of the user
+	if stored.RevokedAt != nil {
+		if err := h.Tokens.RevokeUserRefresh(ctx.UserContext(), stored.UserID); err != nil {
+			return utils.InternalServerErrorResponse(ctx, "Failed to refresh tokens", err.Error())
+		}
+		return utils.UnauthorizedResponse(ctx, "Invalid refresh token", errRefreshTokenUsed.Error())
+	}
+
+	// revoke the presented token and issue its replacement
+	var session models.AuthSession
+	err = h.Tx.WithinTransaction(ctx.UserContext(), func(tx context.Context) error {
+		revoked, err := h.Tokens.RevokeRefresh(tx, stored.JTI)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			return errRefreshTokenUsed
+		}
+
+		if session.User, err = h.Users.Get(tx, stored.UserID); err != nil {
+			return err
+		}
+		session.Tokens, err = h.issueTokens(tx, session.User)
+		return err
+	})
+	if errors.Is(err, errRefreshTokenUsed) || errors.Is(err, repositories.ErrNotFound) {
+		return utils.UnauthorizedResponse(ctx, "Invalid refresh token", err.Error())
+	}
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to refresh tokens", err.Error())
+	}
+
+	// return success response with the user and its new tokens
+	return utils.OKResponse(ctx, "Tokens refreshed successfully", session)
+}
+
+// Logout godoc
+// @Summary      Log out
+// @Description  revoke the access token of the request and the given refresh token, or every refresh token of the user when none is given
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        token  body      models.LogoutRequest  false  "Refresh token to revoke"
+// @Success      200  {object}  utils.SuccessResponse "Logged out successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid request body"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to log out"
+// @Router       /api/auth/logout [post]
+func (h *Handler) Logout(ctx *fiber.Ctx) error {
+	// the access token of the request has been verified by the auth middleware
+	claims := middlewares.CurrentUser(ctx)
+	if claims == nil {
+		return utils.UnauthorizedResponse(ctx, "Authentication required", "Send the access token to revoke in the Authorization header")
+	}
+
+	// parse the optional request body
+	req := new(models.LogoutRequest)
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(req); err != nil {
+			return utils.BadRequestResponse(ctx, "Invalid request body", err.Error())
+		}
+	}
+
+	// revoke the access token and the refresh tokens
+	err := h.Tx.WithinTransaction(ctx.UserContext(), func(tx context.Context) error {
+		revoked := models.RevokedToken{JTI: claims.ID, ExpiresAt: claims.ExpiresAt.Time}
+		if err := h.Tokens.RevokeAccess(tx, &revoked); err != nil {
+			return err
+		}
+
+		if req.RefreshToken == "" {
+			return h.Tokens.RevokeUserRefresh(tx, claims.UserID())
+		}
+		refresh, err := token.Parse(req.RefreshToken, token.TypeRefresh)
+		if err != nil || refresh.UserID() != claims.UserID() {
+			return nil
+		}
+		_, err = h.Tokens.RevokeRefresh(tx, refresh.ID)
+		return err
+	})
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to log out", err.Error())
+	}
+
+	// return success response
+	return utils.OKResponse(ctx, "Logged out successfully", nil)
+}
+
+// issueTokens issues a token pair for the user and stores its refresh token.
+func (h *Handler) issueTokens(ctx context.Context, user *models.User) (*token.Pair, error) {
+	tokens, refresh, err := token.NewPair(user.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.Tokens.CreateRefresh(ctx, &models.RefreshToken{
+		UserID:    user.ID,
+		JTI:       refresh.ID,
+		ExpiresAt: refresh.ExpiresAt.Time,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+// ListMovieCredits godoc
+// @Summary      List movie credits
+// @Description  get the cast and crew of a movie ordered by role and billing order
+// @Tags         movies
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Movie ID"
+// @Success      200  {object}  utils.SuccessResponse{data=[]models.Credit} "Credits fetched successfully"
+// @Failure      404  {object}  utils.ErrorResponse "Movie not found"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to fetch credits"
+// @Router       /api/movies/{id}/credits [get]
+func (h *Handler) ListMovieCredits(ctx *fiber.Ctx) error {
+	// fetch the movie by ID
+	movie, err := h.Movies.Get(ctx.UserContext(), paramID(ctx, "id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Movie not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch credits", err.Error())
+	}
+
+	// fetch the credits of the movie with the credited people
+	credits, err := h.Credits.ListByMovie(ctx.UserContext(), movie.ID)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch credits", err.Error())
+	}
+
+	// return success response with credits data
+	return utils.OKResponse(ctx, "Credits fetched successfully", credits)
+}
+
+// CreateMovieCredit godoc
+// @Summary      Add a movie credit
+// @Description  credit a person on a movie with a role, character name and billing order
+// @Tags         movies
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id      path      string         true  "Movie ID"
+// @Param        credit  body      models.Credit  true  "Credit data"
+// @Success      201  {object}  utils.SuccessResponse{data=models.Credit} "Credit created successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid request body or validation failed"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      404  {object}  utils.ErrorResponse "Movie not found"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to create credit"
+// @Router       /api/movies/{id}/credits [post]
+func (h *Handler) CreateMovieCredit(ctx *fiber.Ctx) error {
+	// fetch the movie by ID
+	movie, err := h.Movies.Get(ctx.UserContext(), paramID(ctx, "id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Movie not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to create credit", err.Error())
+	}
+
+	// initialize a new credit instance and parse the request body
+	credit := new(models.Credit)
+	if err := ctx.BodyParser(credit); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid request body", err.Error())
+	}
+
+	// validate the credit struct
+	if err := validators.ValidateStruct(credit); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// make sure the credited person exists
+	person, err := h.People.Get(ctx.UserContext(), credit.PersonID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.BadRequestResponse(ctx, "Validation failed", []string{"PersonID: Unknown person"})
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to create credit", err.Error())
+	}
+
+	// create the credit record
+	credit.MovieID = movie.ID
+	credit.Movie = nil
+	credit.Person = nil
+	if err := h.Credits.Create(ctx.UserContext(), credit); err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to create credit", err.Error())
+	}
+
+	// return success response
+	credit.Person = person
+	return utils.CreatedResponse(ctx, "Credit created successfully", credit)
+}
+
+// DeleteMovieCredit godoc
+// @Summary      Remove a movie credit
+// @Description  delete a credit from a movie
+// @Tags         movies
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id        path      string  true  "Movie ID"
+// @Param        creditId  path      string  true  "Credit ID"
+// @Success      200  {object}  utils.SuccessResponse "Credit deleted successfully"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      404  {object}  utils.ErrorResponse "Credit not found"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to delete credit"
+// @Router       /api/movies/{id}/credits/{creditId} [delete]
+func (h *Handler) DeleteMovieCredit(ctx *fiber.Ctx) error {
+	// fetch the credit of the movie
+	credit, err := h.Credits.Get(ctx.UserContext(), paramID(ctx, "id"), paramID(ctx, "creditId"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Credit not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to delete credit", err.Error())
+	}
+
+	// delete the credit record
+	if err := h.Credits.Delete(ctx.UserContext(), credit); err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to delete credit", err.Error())
+	}
+
+	// return success response
+	return utils.OKResponse(ctx, "Credit deleted successfully", nil)
+}
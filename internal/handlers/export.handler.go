@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+// exportContentTypes maps the export formats to their content type.
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"ndjson": "application/x-ndjson",
+	"json":   fiber.MIMEApplicationJSONCharsetUTF8,
+}
+
+// exportFlushRows is the number of rows between the flushes of an export.
+const exportFlushRows = 500
+
+// exportRetryAfter is the delay clients are told to wait for when the
+// exports in progress are at the limit, in seconds.
+const exportRetryAfter = "10"
+
+// ExportMovies godoc
+// @Summary      Export movies
+// @Description  stream the movies matching the filters, ordered by ID, as CSV with a header row, NDJSON or a JSON array.
+// @Description  Genres are exported as slugs, separated by "|" in CSV like imports take them. Errors past the first row truncate the stream.
+// @Description  Only a few exports run at once, the stream stops when the client stops reading for longer than the write timeout.
+// @Tags         movies
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        format        query     string  false  "Export format, csv by default"  Enums(csv, ndjson, json)
+// @Param        columns       query     string  false  "Comma-separated columns, all by default: id, title, description, poster_url, release_date, rating, duration_minutes, director, genres, version, created_at, updated_at"
+// @Param        gzip          query     bool    false  "Compress the export with gzip"
+// @Param        director      query     string  false  "Director name (partial match)"
+// @Param        genre         query     string  false  "Genre slug or name"
+// @Param        year_from     query     int     false  "Minimum release year"
+// @Param        year_to       query     int     false  "Maximum release year"
+// @Param        rating_min    query     number  false  "Minimum rating"
+// @Param        rating_max    query     number  false  "Maximum rating"
+// @Param        duration_min  query     int     false  "Minimum duration in minutes"
+// @Param        duration_max  query     int     false  "Maximum duration in minutes"
+// @Success      200  {string}  string "Movies export"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid query parameters"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to export movies"
+// @Failure      503  {object}  utils.ErrorResponse "Too many exports in progress"
+// @Router       /api/movies/export [get]
+func (h *Handler) ExportMovies(ctx *fiber.Ctx) error {
+	// parse the query parameters
+	query := new(queries.MovieExportQuery)
+	if err := ctx.QueryParser(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid query parameters", err.Error())
+	}
+
+	// validate the query parameters
+	if err := validators.ValidateStruct(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+	columns, err := query.SelectedColumns()
+	if err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid query parameters", err.Error())
+	}
+	format := strings.Clone(query.ExportFormat())
+
+	// exports hold a database connection while they stream, only a few run at once
+	select {
+	case h.exports <- struct{}{}:
+	default:
+		ctx.Set(fiber.HeaderRetryAfter, exportRetryAfter)
+		return utils.ServiceUnavailableResponse(ctx, "Too many exports in progress", "Retry the export later")
+	}
+	release := func() { <-h.exports }
+
+	// open a cursor over the matching movies
+	movies, err := h.Movies.Export(ctx.UserContext(), &query.MovieFilterQuery, columns)
+	if err != nil {
+		release()
+		return utils.InternalServerErrorResponse(ctx, "Failed to export movies", err.Error())
+	}
+
+	// describe the download
+	filename := "movies." + format
+	ctx.Set(fiber.HeaderContentType, exportContentTypes[format])
+	ctx.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	if query.Gzip {
+		ctx.Set(fiber.HeaderContentEncoding, "gzip")
+	}
+
+	// stream the rows as the client reads them, every write must complete
+	// within the write timeout so that stalled clients release the cursor
+	gzipped := query.Gzip
+	conn, timeout := ctx.Context().Conn(), h.ExportWriteTimeout
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer release()
+		defer movies.Close()
+		defer conn.SetWriteDeadline(time.Time{})
+
+		stream := &deadlineWriter{w: w, conn: conn, timeout: timeout}
+		var out io.Writer = stream
+		flush := stream.Flush
+		var compressor *gzip.Writer
+		if gzipped {
+			compressor = gzip.NewWriter(stream)
+			out = compressor
+			flush = func() error {
+				if err := compressor.Flush(); err != nil {
+					return err
+				}
+				return stream.Flush()
+			}
+		}
+
+		err := writeExport(movies, out, flush, format, columns)
+		if compressor != nil && err == nil {
+			err = compressor.Close()
+		}
+		if err == nil {
+			err = stream.Flush()
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("format", format).Msg("Movie export interrupted")
+		}
+	})
+	return nil
+}
+
+// deadlineWriter gives every write to the connection, which the buffered
+// writer makes as its buffer fills, and every flush the write timeout.
+type deadlineWriter struct {
+	w       *bufio.Writer
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (d *deadlineWriter) Write(p []byte) (int, error) {
+	if err := d.extend(); err != nil {
+		return 0, err
+	}
+	return d.w.Write(p)
+}
+
+func (d *deadlineWriter) Flush() error {
+	if err := d.extend(); err != nil {
+		return err
+	}
+	return d.w.Flush()
+}
+
+func (d *deadlineWriter) extend() error {
+	if d.timeout <= 0 {
+		return nil
+	}
+	return d.conn.SetWriteDeadline(time.Now().Add(d.timeout))
+}
+
+// writeExport writes the rows of the cursor in the export format, flushing
+// every exportFlushRows rows so that clients receive the export as it goes.
+func writeExport(movies repositories.MovieCursor, w io.Writer, flush func() error, format string, columns []string) error {
+	var records *csv.Writer
+	switch format {
+	case "csv":
+		records = csv.NewWriter(w)
+		if err := records.Write(columns); err != nil {
+			return err
+		}
+	case "json":
+		if _, err := io.WriteString(w, "[\n"); err != nil {
+			return err
+		}
+	}
+
+	record := make([]string, len(columns))
+	var object bytes.Buffer
+	n := 0
+	for ; movies.Next(); n++ {
+		movie, err := movies.Movie()
+		if err != nil {
+			return err
+		}
+
+		if records != nil {
+			for i, column := range columns {
+				record[i] = exportText(movie, column)
+			}
+			if err := records.Write(record); err != nil {
+				return err
+			}
+		} else {
+			object.Reset()
+			if format == "json" && n > 0 {
+				object.WriteString(",\n")
+			}
+			if err := writeExportJSON(&object, movie, columns); err != nil {
+				return err
+			}
+			if format == "ndjson" {
+				object.WriteByte('\n')
+			}
+			if _, err := w.Write(object.Bytes()); err != nil {
+				return err
+			}
+		}
+
+		if n%exportFlushRows == exportFlushRows-1 {
+			if err := flushExport(records, flush); err != nil {
+				return err
+			}
+		}
+	}
+	if err := movies.Err(); err != nil {
+		return err
+	}
+
+	if format == "json" {
+		closing := "\n]\n"
+		if n == 0 {
+			closing = "]\n"
+		}
+		if _, err := io.WriteString(w, closing); err != nil {
+			return err
+		}
+	}
+	return flushExport(records, flush)
+}
+
+// flushExport flushes the buffered rows of an export down to the connection.
+func flushExport(records *csv.Writer, flush func() error) error {
+	if records != nil {
+		records.Flush()
+		if err := records.Error(); err != nil {
+			return err
+		}
+	}
+	return flush()
+}
+
+// exportValue returns the value of a column, with genres as a list of slugs.
+func exportValue(m *models.Movie, column string) interface{} {
+	switch column {
+	case "id":
+		return m.ID
+	case "title":
+		return m.Title
+	case "description":
+		return m.Description
+	case "poster_url":
+		return m.PosterURL
+	case "release_date":
+		if len(m.ReleaseDate) > len("2006-01-02") {
+			return m.ReleaseDate[:len("2006-01-02")]
+		}
+		return m.ReleaseDate
+	case "rating":
+		return m.Rating
+	case "duration_minutes":
+		return m.DurationMinutes
+	case "director":
+		return m.Director
+	case "genres":
+		slugs := make([]string, len(m.Genres))
+		for i, genre := range m.Genres {
+			slugs[i] = genre.Slug
+		}
+		return slugs
+	case "version":
+		return m.Version
+	case "created_at":
+		return m.CreatedAt
+	default:
+		return m.UpdatedAt
+	}
+}
+
+// exportText returns the value of a column as a CSV field.
+func exportText(m *models.Movie, column string) string {
+	switch value := exportValue(m, column).(type) {
+	case string:
+		return value
+	case []string:
+		return strings.Join(value, "|")
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(value)
+	case uint:
+		return strconv.FormatUint(uint64(value), 10)
+	case time.Time:
+		return value.UTC().Format(time.RFC3339)
+	default:
+		return ""
+	}
+}
+
+// writeExportJSON writes the movie as a JSON object holding the columns in order.
+func writeExportJSON(buf *bytes.Buffer, m *models.Movie, columns []string) error {
+	buf.WriteByte('{')
+	for i, column := range columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		value, err := json.Marshal(exportValue(m, column))
+		if err != nil {
+			return err
+		}
+		buf.WriteString(strconv.Quote(column))
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return nil
+}
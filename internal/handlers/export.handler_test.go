@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestExportMovies(t *testing.T) {
+	runBackends(t, func(t *testing.T, app *testApp) {
+		createMovies(t, app)
+
+		res := app.do(t, fiber.MethodGet, "/api/movies/export?columns=title,genres&director=coppola", nil).expect(t, fiber.StatusOK)
+		rows, err := csv.NewReader(bytes.NewReader(res.raw)).ReadAll()
+		if err != nil {
+			t.Fatalf("read export %q: %v", res.raw, err)
+		}
+		if len(rows) != 3 || rows[0][0] != "title" || rows[1][0] != "The Godfather" || rows[1][1] != "crime|drama" {
+			t.Errorf("export = %v, want the header and both Coppola movies", rows)
+		}
+
+		// exports beyond the limit are refused until one completes
+		app.h.exports <- struct{}{}
+		res = app.do(t, fiber.MethodGet, "/api/movies/export", nil).expect(t, fiber.StatusServiceUnavailable)
+		if res.header.Get(fiber.HeaderRetryAfter) != exportRetryAfter {
+			t.Errorf("Retry-After = %q, want %s", res.header.Get(fiber.HeaderRetryAfter), exportRetryAfter)
+		}
+		<-app.h.exports
+		app.do(t, fiber.MethodGet, "/api/movies/export?format=ndjson", nil).expect(t, fiber.StatusOK)
+		app.do(t, fiber.MethodGet, "/api/movies/export?columns=budget", nil).expect(t, fiber.StatusBadRequest)
+	})
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+// ListGenres godoc
+// @Summary      List genres
+// @Description  get list of all genres ordered by name
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  utils.SuccessResponse{data=[]models.Genre} "Genres fetched successfully"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to fetch genres"
+// @Router       /api/genres [get]
+func (h *Handler) ListGenres(ctx *fiber.Ctx) error {
+	// fetch all genres
+	genres, err := h.Genres.List(ctx.UserContext())
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch genres", err.Error())
+	}
+
+	// return success response with genres data
+	return utils.OKResponse(ctx, "Genres fetched successfully", genres)
+}
+
+// GetGenre godoc
+// @Summary      Get a genre
+// @Description  get genre by slug
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Param        slug  path      string  true  "Genre slug"
+// @Success      200  {object}  utils.SuccessResponse{data=models.Genre} "Genre fetched successfully"
+// @Failure      404  {object}  utils.ErrorResponse "Genre not found"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to fetch genre"
+// @Router       /api/genres/{slug} [get]
+func (h *Handler) GetGenre(ctx *fiber.Ctx) error {
+	// fetch the genre by slug
+	genre, err := h.Genres.GetBySlug(ctx.UserContext(), ctx.Params("slug"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Genre not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch genre", err.Error())
+	}
+
+	// return success response with genre data
+	return utils.OKResponse(ctx, "Genre fetched successfully", genre)
+}
+
+// CreateGenre godoc
+// @Summary      Create a genre
+// @Description  create a new genre, the slug is derived from the name when omitted
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        genre  body      models.Genre  true  "Genre data"
+// @Success      201  {object}  utils.SuccessResponse{data=models.Genre} "Genre created successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid request body or validation failed"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      409  {object}  utils.ErrorResponse "Genre slug already exists"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to create genre"
+// @Router       /api/genres [post]
+func (h *Handler) CreateGenre(ctx *fiber.Ctx) error {
+	// initialize a new genre instance
+	genre := new(models.Genre)
+
+	// parse the request body
+	if err := ctx.BodyParser(genre); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid request body", err.Error())
+	}
+
+	// derive the slug from the name when omitted
+	if genre.Slug == "" {
+		genre.Slug = utils.Slugify(genre.Name)
+	}
+
+	// validate the genre struct
+	if err := validators.ValidateStruct(genre); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// make sure the slug is not taken
+	if taken, err := h.Genres.SlugTaken(ctx.UserContext(), genre.Slug, 0); err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to create genre", err.Error())
+	} else if taken {
+		return utils.ConflictResponse(ctx, "Genre slug already exists", genre.Slug)
+	}
+
+	// create the genre record
+	if err := h.Genres.Create(ctx.UserContext(), genre); err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to create genre", err.Error())
+	}
+
+	// return success response
+	return utils.CreatedResponse(ctx, "Genre created successfully", genre)
+}
+
+// UpdateGenre godoc
+// @Summary      Update a genre
+// @Description  update an existing genre by slug
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        slug   path      string        true  "Genre slug"
+// @Param        genre  body      models.Genre  true  "Updated genre data"
+// @Success      200  {object}  utils.SuccessResponse{data=models.Genre} "Genre updated successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid request body or validation failed"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      404  {object}  utils.ErrorResponse "Genre not found"
+// @Failure      409  {object}  utils.ErrorResponse "Genre slug already exists"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to update genre"
+// @Router       /api/genres/{slug} [put]
+func (h *Handler) UpdateGenre(ctx *fiber.Ctx) error {
+	// fetch the existing genre
+	genre, err := h.Genres.GetBySlug(ctx.UserContext(), ctx.Params("slug"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Genre not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to update genre", err.Error())
+	}
+
+	// initialize a new genre instance to hold the updated data and parse the request body
+	req := new(models.Genre)
+	if err := ctx.BodyParser(req); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid request body", err.Error())
+	}
+
+	// keep the current slug when omitted
+	if req.Slug == "" {
+		req.Slug = genre.Slug
+	}
+
+	// validate the updated genre data
+	if err := validators.ValidateStruct(req); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// make sure the new slug is not taken by another genre
+	if taken, err := h.Genres.SlugTaken(ctx.UserContext(), req.Slug, genre.ID); err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to update genre", err.Error())
+	} else if taken {
+		return utils.ConflictResponse(ctx, "Genre slug already exists", req.Slug)
+	}
+
+	// update the genre record
+	genre.Name = req.Name
+	genre.Slug = req.Slug
+	if err := h.Genres.Update(ctx.UserContext(), genre); err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to update genre", err.Error())
+	}
+
+	// return success response
+	return utils.OKResponse(ctx, "Genre updated successfully", genre)
+}
+
+// DeleteGenre godoc
+// @Summary      Delete a genre
+// @Description  delete a genre by slug, genres still assigned to movies cannot be deleted
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        slug  path      string  true  "Genre slug"
+// @Success      200  {object}  utils.SuccessResponse "Genre deleted successfully"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      404  {object}  utils.ErrorResponse "Genre not found"
+// @Failure      409  {object}  utils.ErrorResponse "Genre is still assigned to movies"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to delete genre"
+// @Router       /api/genres/{slug} [delete]
+func (h *Handler) DeleteGenre(ctx *fiber.Ctx) error {
+	// fetch the existing genre
+	genre, err := h.Genres.GetBySlug(ctx.UserContext(), ctx.Params("slug"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Genre not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to delete genre", err.Error())
+	}
+
+	// refuse to orphan the movies using this genre
+	movies, err := h.Movies.CountByGenre(ctx.UserContext(), genre.ID)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to delete genre", err.Error())
+	}
+	if movies > 0 {
+		return utils.ConflictResponse(ctx, "Genre is still assigned to movies", fmt.Sprintf("%d movies use this genre", movies))
+	}
+
+	// delete the genre record, movies assigned to it meanwhile keep it
+	if err := h.Genres.Delete(ctx.UserContext(), genre); err != nil {
+		if errors.Is(err, repositories.ErrInUse) {
+			return utils.ConflictResponse(ctx, "Genre is still assigned to movies", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to delete genre", err.Error())
+	}
+
+	// return success response
+	return utils.OKResponse(ctx, "Genre deleted successfully", nil)
+}
+
+// ListGenreMovies godoc
+// @Summary      List movies of a genre
+// @Description  get a paginated list of the movies in a genre, accepts the list movies parameters
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Param        slug      path      string  true   "Genre slug"
+// @Param        page      query     int     false  "Page number"  minimum(1)
+// @Param        per_page  query     int     false  "Movies per page"  minimum(1)  maximum(100)
+// @Param        cursor    query     string  false  "Opaque cursor for keyset pagination"
+// @Param        sort      query     string  false  "Sort field"  Enums(title, release_date, rating, duration_minutes, created_at)
+// @Param        order     query     string  false  "Sort direction"  Enums(asc, desc)
+// @Success      200  {object}  utils.SuccessResponse{data=[]models.Movie,meta=utils.Pagination} "Movies fetched successfully"
+// @Failure      204  {object}  utils.ErrorResponse "Movies data is empty"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid query parameters"
+// @Failure      404  {object}  utils.ErrorResponse "Genre not found"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to fetch movies"
+// @Router       /api/genres/{slug}/movies [get]
+func (h *Handler) ListGenreMovies(ctx *fiber.Ctx) error {
+	// fetch the genre by slug
+	genre, err := h.Genres.GetBySlug(ctx.UserContext(), ctx.Params("slug"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Genre not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch genre", err.Error())
+	}
+
+	// parse the query parameters
+	query := new(queries.MovieListQuery)
+	if err := ctx.QueryParser(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid query parameters", err.Error())
+	}
+
+	// validate the query parameters
+	if err := validators.ValidateStruct(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// restrict the list to the genre
+	query.Genre = genre.Slug
+
+	return h.listMovies(ctx, query)
+}
+
+// resolveGenres loads the genres referenced by slug or ID, in one query.
+// Unknown references are reported as validation messages.
+func (h *Handler) resolveGenres(ctx context.Context, refs []models.Genre) ([]models.Genre, []string, error) {
+	var ids []uint
+	var slugs []string
+	for _, ref := range refs {
+		switch {
+		case ref.ID != 0:
+			ids = append(ids, ref.ID)
+		case ref.Slug != "":
+			slugs = append(slugs, ref.Slug)
+		}
+	}
+
+	found, err := h.Genres.FindByRefs(ctx, ids, slugs)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[uint]models.Genre, len(found))
+	bySlug := make(map[string]models.Genre, len(found))
+	for _, genre := range found {
+		byID[genre.ID] = genre
+		bySlug[genre.Slug] = genre
+	}
+
+	// keep the order of the references
+	genres := make([]models.Genre, 0, len(refs))
+	var invalid []string
+	for _, ref := range refs {
+		var genre models.Genre
+		var ok bool
+
+		switch {
+		case ref.ID != 0:
+			genre, ok = byID[ref.ID]
+			if !ok {
+				invalid = append(invalid, fmt.Sprintf("Genres: Unknown genre %q", strconv.FormatUint(uint64(ref.ID), 10)))
+				continue
+			}
+		case ref.Slug != "":
+			genre, ok = bySlug[ref.Slug]
+			if !ok {
+				invalid = append(invalid, fmt.Sprintf("Genres: Unknown genre %q", ref.Slug))
+				continue
+			}
+		default:
+			invalid = append(invalid, "Genres: Genre reference must be a slug or an ID")
+			continue
+		}
+
+		genres = append(genres, genre)
+	}
+
+	return genres, invalid, nil
+}
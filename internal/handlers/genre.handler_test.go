@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+)
+
+func TestDeleteGenre(t *testing.T) {
+	runBackends(t, func(t *testing.T, app *testApp) {
+		app.do(t, fiber.MethodPost, "/api/movies", newMovieBody("Heat", "1995-12-15", 8.3, 170, "Michael Mann", "crime")).
+			expect(t, fiber.StatusCreated)
+
+		// genres assigned to movies are kept, the others are deleted
+		app.do(t, fiber.MethodDelete, "/api/genres/crime", nil).expect(t, fiber.StatusConflict)
+		app.do(t, fiber.MethodDelete, "/api/genres/drama", nil).expect(t, fiber.StatusOK)
+		app.do(t, fiber.MethodDelete, "/api/genres/drama", nil).expect(t, fiber.StatusNotFound)
+
+		// the databases refuse it too, when a movie got the genre after the check
+		if _, ok := app.h.Genres.(*repositories.MemoryGenreRepository); ok {
+			return
+		}
+		crime, err := app.h.Genres.GetBySlug(context.Background(), "crime")
+		if err != nil {
+			t.Fatalf("get crime: %v", err)
+		}
+		if err := app.h.Genres.Delete(context.Background(), crime); !errors.Is(err, repositories.ErrInUse) {
+			t.Errorf("delete crime = %v, want ErrInUse", err)
+		}
+	})
+}
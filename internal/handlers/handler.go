@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// Handler serves the API endpoints. Its dependencies are injected, so movie
+// handlers can run against the in-memory repositories instead of a database.
+type Handler struct {
+	Tx         repositories.Transactor
+	Movies     repositories.MovieRepository
+	Genres     repositories.GenreRepository
+	Revisions  repositories.RevisionRepository
+	ImportJobs repositories.ImportJobRepository
+	People     repositories.PersonRepository
+	Credits    repositories.CreditRepository
+	Users      repositories.UserRepository
+	Tokens     repositories.TokenRepository
+	APIKeys    repositories.APIKeyRepository
+
+	// ImportSyncRows is the number of rows above which imports run in the background.
+	ImportSyncRows int
+	// ExportWriteTimeout bounds every write of an export to the client.
+	ExportWriteTimeout time.Duration
+
+	imports backgroundJobs
+	// exports holds a token for every export in progress.
+	exports chan struct{}
+}
+
+// backgroundJobs tracks the work running past its request, which is
+// cancelled on shutdown.
+type backgroundJobs struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New returns a Handler backed by the database.
+func New(db *gorm.DB, config *config.Config) *Handler {
+	return configure(&Handler{
+		Tx:         repositories.NewTransactor(db),
+		Movies:     repositories.NewMovieRepository(db),
+		Genres:     repositories.NewGenreRepository(db),
+		Revisions:  repositories.NewRevisionRepository(db),
+		ImportJobs: repositories.NewImportJobRepository(db),
+		People:     repositories.NewPersonRepository(db),
+		Credits:    repositories.NewCreditRepository(db),
+		Users:      repositories.NewUserRepository(db),
+		Tokens:     repositories.NewTokenRepository(db),
+		APIKeys:    repositories.NewAPIKeyRepository(db),
+	}, config)
+}
+
+// configure applies the limits of the configuration to the handler and
+// prepares its background work.
+func configure(h *Handler, config *config.Config) *Handler {
+	h.ImportSyncRows = config.ImportSyncRows
+	h.ExportWriteTimeout = config.ExportWriteTimeout
+	h.exports = make(chan struct{}, max(config.ExportMaxConcurrent, 1))
+	h.imports.ctx, h.imports.cancel = context.WithCancel(context.Background())
+	return h
+}
+
+// StopImports cancels the imports running in the background and waits for
+// them to record where they stopped.
+func (h *Handler) StopImports(ctx context.Context) error {
+	h.imports.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		h.imports.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// paramID returns the ID in a URL parameter, zero when it is not a valid ID.
+func paramID(ctx *fiber.Ctx, key string) uint {
+	id, err := strconv.ParseUint(ctx.Params(key), 10, 0)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
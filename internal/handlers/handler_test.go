@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	gomysql "github.com/go-sql-driver/mysql"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+	"github.com/zdacoder/go-fiber-movie-app-api/config/database"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/cursor"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	validators.Init()
+	cursor.Init(&config.Config{CursorSecret: "handler-test-cursor-secret"})
+	os.Exit(m.Run())
+}
+
+// testConfig holds the limits of the handlers under test.
+var testConfig = &config.Config{
+	ImportSyncRows:      100,
+	ExportMaxConcurrent: 1,
+	ExportWriteTimeout:  time.Second,
+}
+
+// testBackends build a handler over each storage the handler tests run
+// against. PostgreSQL and MySQL run on the servers of DATABASE_URL and
+// MYSQL_DSN, and are skipped when the variables are not set.
+var testBackends = []struct {
+	name    string
+	handler func(t *testing.T) *Handler
+}{
+	{"memory", newMemoryHandler},
+	{"sqlite", newSQLiteHandler},
+	{"postgres", newPostgresHandler},
+	{"mysql", newMySQLHandler},
+}
+
+// testGenres are created on every backend before the tests run.
+var testGenres = []models.Genre{
+	{Name: "Crime", Slug: "crime"},
+	{Name: "Drama", Slug: "drama"},
+	{Name: "Science Fiction", Slug: "science-fiction"},
+}
+
+func newMemoryHandler(t *testing.T) *Handler {
+	return configure(&Handler{
+		Tx:         repositories.MemoryTransactor{},
+		Movies:     repositories.NewMemoryMovieRepository(),
+		Genres:     repositories.NewMemoryGenreRepository(),
+		Revisions:  repositories.NewMemoryRevisionRepository(),
+		ImportJobs: repositories.NewMemoryImportJobRepository(),
+	}, testConfig)
+}
+
+func newSQLiteHandler(t *testing.T) *Handler {
+	return newDatabaseHandler(t, sqlite.Open("file::memory:?_pragma=foreign_keys(1)"))
+}
+
+// newPostgresHandler migrates a schema of its own, dropped after the test.
+func newPostgresHandler(t *testing.T) *Handler {
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	schema := testDatabaseName("handlers")
+	admin := openTestDB(t, postgres.Open(dsn))
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	if !strings.Contains(dsn, "://") {
+		return newDatabaseHandler(t, postgres.Open(dsn+" search_path="+schema+",public"))
+	}
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return newDatabaseHandler(t, postgres.Open(dsn+separator+"search_path="+schema+",public"))
+}
+
+// newMySQLHandler migrates a database of its own, dropped after the test.
+func newMySQLHandler(t *testing.T) *Handler {
+	dsn := os.Getenv("MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_DSN is not set")
+	}
+	cfg, err := gomysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("invalid MYSQL_DSN: %v", err)
+	}
+
+	name := testDatabaseName("handlers")
+	admin := openTestDB(t, mysql.Open(dsn))
+	if err := admin.Exec("CREATE DATABASE " + name).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP DATABASE " + name) })
+
+	// migration files hold several statements
+	cfg.DBName = name
+	cfg.ParseTime = true
+	cfg.MultiStatements = true
+	return newDatabaseHandler(t, mysql.Open(cfg.FormatDSN()))
+}
+
+// testDatabaseName returns a name no other test run uses.
+func testDatabaseName(prefix string) string {
+	return prefix + "_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+// openTestDB opens a database closed after the test.
+func openTestDB(t *testing.T, dialector gorm.Dialector) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("open %s: %v", dialector.Name(), err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection to an in-memory SQLite database opens a new one
+	if dialector.Name() == database.DriverSQLite {
+		sqlDB.SetMaxOpenConns(1)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// newDatabaseHandler migrates the database up and returns a handler over it.
+func newDatabaseHandler(t *testing.T, dialector gorm.Dialector) *Handler {
+	t.Helper()
+	db := openTestDB(t, dialector)
+
+	// the migrations run on the database of the package
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+	if _, err := database.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("migrate %s: %v", dialector.Name(), err)
+	}
+	return New(db, testConfig)
+}
+
+// runBackends runs the test against a fresh handler over every backend,
+// holding the test genres.
+func runBackends(t *testing.T, test func(t *testing.T, app *testApp)) {
+	for _, backend := range testBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			h := backend.handler(t)
+			t.Cleanup(func() { h.StopImports(context.Background()) })
+
+			for _, genre := range testGenres {
+				if err := h.Genres.Create(context.Background(), &genre); err != nil {
+					t.Fatalf("create genre %s: %v", genre.Slug, err)
+				}
+			}
+			test(t, newTestApp(h))
+		})
+	}
+}
+
+// testApp serves the movie endpoints of a handler, without the
+// authentication and permission middlewares.
+type testApp struct {
+	*fiber.App
+	h *Handler
+}
+
+func newTestApp(h *Handler) *testApp {
+	app := fiber.New()
+
+	movies := app.Group("/api/movies")
+	movies.Get("/", h.ListMovies)
+	movies.Get("/search", h.SearchMovies)
+	movies.Get("/autocomplete", h.AutocompleteMovies)
+	movies.Get("/export", h.ExportMovies)
+	movies.Get("/trash", h.ListTrashedMovies)
+	movies.Post("/import", h.ImportMovies)
+	movies.Get("/import/:id", h.GetImportJob)
+	movies.Get("/:id", h.GetMovie)
+	movies.Post("/", h.CreateMovie)
+	movies.Put("/:id", h.UpdateMovie)
+	movies.Patch("/:id", h.PatchMovie)
+	movies.Delete("/:id", h.DeleteMovie)
+	movies.Post("/:id/restore", h.RestoreMovie)
+	movies.Get("/:id/history", h.ListMovieHistory)
+	movies.Get("/:id/history/:rev", h.GetMovieRevision)
+	movies.Post("/:id/revert/:rev", h.RevertMovie)
+	app.Get("/api/genres/:slug/movies", h.ListGenreMovies)
+	app.Delete("/api/genres/:slug", h.DeleteGenre)
+
+	return &testApp{App: app, h: h}
+}
+
+// testResponse is a response of the test app with its raw body and its
+// decoded envelope.
+type testResponse struct {
+	status int
+	header http.Header
+	raw    []byte
+	body   struct {
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+		Meta    json.RawMessage `json:"meta"`
+		Error   json.RawMessage `json:"error"`
+	}
+}
+
+// do sends a request with a JSON body, when not nil, and headers given as
+// name and value pairs.
+func (a *testApp) do(t *testing.T, method, target string, body interface{}, headers ...string) *testResponse {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != nil {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	return a.send(t, req)
+}
+
+// send sends a request and decodes the envelope of its JSON response.
+func (a *testApp) send(t *testing.T, req *http.Request) *testResponse {
+	t.Helper()
+
+	method, target := req.Method, req.URL.RequestURI()
+	resp, err := a.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, target, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := &testResponse{status: resp.StatusCode, header: resp.Header, raw: raw}
+	if len(raw) > 0 && strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+		if err := json.Unmarshal(raw, &res.body); err != nil {
+			t.Fatalf("%s %s: decode %q: %v", method, target, raw, err)
+		}
+	}
+	return res
+}
+
+// expect fails the test when the response does not have the status.
+func (r *testResponse) expect(t *testing.T, status int) *testResponse {
+	t.Helper()
+	if r.status != status {
+		t.Fatalf("status = %d, want %d: %s %s", r.status, status, r.body.Message, r.body.Error)
+	}
+	return r
+}
+
+// decode decodes raw JSON of the response into a value of type T.
+func decode[T any](t *testing.T, raw json.RawMessage) T {
+	t.Helper()
+	var value T
+	if err := json.Unmarshal(raw, &value); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+	return value
+}
+
+// testMovies are created by createMovies, in order.
+var testMovies = []map[string]interface{}{
+	newMovieBody("The Godfather", "1972-03-24", 9.2, 175, "Francis Ford Coppola", "crime", "drama"),
+	newMovieBody("The Godfather Part II", "1974-12-20", 9.0, 202, "Francis Ford Coppola", "crime", "drama"),
+	newMovieBody("Alien", "1979-05-25", 8.5, 117, "Ridley Scott", "science-fiction"),
+	newMovieBody("Blade Runner", "1982-06-25", 8.1, 117, "Ridley Scott", "science-fiction", "drama"),
+	newMovieBody("Heat", "1995-12-15", 8.3, 170, "Michael Mann", "crime"),
+}
+
+// newMovieBody returns the request body of a movie with the genres given by slug.
+func newMovieBody(title, releaseDate string, rating float64, duration int, director string, genres ...string) map[string]interface{} {
+	refs := make([]map[string]string, len(genres))
+	for i, slug := range genres {
+		refs[i] = map[string]string{"slug": slug}
+	}
+	return map[string]interface{}{
+		"title":            title,
+		"description":      "A film by " + director + ", " + title,
+		"poster_url":       "https://example.com/posters/" + strings.ToLower(strings.ReplaceAll(title, " ", "-")) + ".jpg",
+		"release_date":     releaseDate,
+		"rating":           rating,
+		"duration_minutes": duration,
+		"director":         director,
+		"genres":           refs,
+	}
+}
+
+// createMovies creates the test movies through the API and returns them by title.
+func createMovies(t *testing.T, app *testApp) map[string]models.Movie {
+	t.Helper()
+	movies := make(map[string]models.Movie, len(testMovies))
+	for _, body := range testMovies {
+		movie := decode[models.Movie](t, app.do(t, fiber.MethodPost, "/api/movies", body).expect(t, fiber.StatusCreated).body.Data)
+		movies[movie.Title] = movie
+	}
+	return movies
+}
+
+// titles returns the titles of the movies in the data of a response.
+func titles(t *testing.T, res *testResponse) []string {
+	t.Helper()
+	movies := decode[[]models.Movie](t, res.body.Data)
+	titles := make([]string, len(movies))
+	for i, movie := range movies {
+		titles[i] = movie.Title
+	}
+	return titles
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/health"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+// Liveness godoc
+// @Summary      Liveness probe
+// @Description  report that the process is up and serving requests, without checking its dependencies
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  utils.SuccessResponse "Service is alive"
+// @Router       /healthz [get]
+func (h *Handler) Liveness(ctx *fiber.Ctx) error {
+	return utils.OKResponse(ctx, "Service is alive", nil)
+}
+
+// Readiness godoc
+// @Summary      Readiness probe
+// @Description  check the database connection and schema, and the other dependencies, with the status and latency of each one
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  utils.SuccessResponse{data=health.Report} "Service is ready"
+// @Failure      503  {object}  utils.ErrorResponse{error=health.Report} "Service is not ready"
+// @Router       /readyz [get]
+func (h *Handler) Readiness(ctx *fiber.Ctx) error {
+	// run every readiness check
+	report := health.Run(ctx.UserContext())
+
+	// return the report, with a failure status when a check is down
+	if report.Status != health.StatusUp {
+		return utils.ServiceUnavailableResponse(ctx, "Service is not ready", report)
+	}
+	return utils.OKResponse(ctx, "Service is ready", report)
+}
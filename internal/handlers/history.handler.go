@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/bytedance/sonic"
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/middlewares"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+// ListMovieHistory godoc
+// @Summary      List movie history
+// @Description  get the paginated audit history of a movie, newest revision first, including trashed and purged movies
+// @Tags         movies
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id        path      string  true   "Movie ID"
+// @Param        page      query     int     false  "Page number"  minimum(1)
+// @Param        per_page  query     int     false  "Revisions per page"  minimum(1)  maximum(100)
+// @Success      200  {object}  utils.SuccessResponse{data=[]models.MovieRevision,meta=utils.Pagination} "Movie history fetched successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid query parameters"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      404  {object}  utils.ErrorResponse "Movie history not found"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to fetch movie history"
+// @Router       /api/movies/{id}/history [get]
+func (h *Handler) ListMovieHistory(ctx *fiber.Ctx) error {
+	// parse the query parameters
+	query := new(queries.PageQuery)
+	if err := ctx.QueryParser(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid query parameters", err.Error())
+	}
+
+	// validate the query parameters
+	if err := validators.ValidateStruct(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// fetch the requested page of revisions and count the revisions of the movie
+	revisions, total, err := h.Revisions.List(ctx.UserContext(), paramID(ctx, "id"), query)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch movie history", err.Error())
+	}
+	if total == 0 {
+		return utils.NotFoundResponse(ctx, "Movie history not found", repositories.ErrNotFound.Error())
+	}
+
+	// return success response with revisions data and pagination metadata
+	pagination := utils.NewPagination(ctx, query.CurrentPage(), query.Size(), total)
+	return utils.PaginatedResponse(ctx, "Movie history fetched successfully", revisions, pagination)
+}
+
+// GetMovieRevision godoc
+// @Summary      Get a movie revision
+// @Description  get one revision of a movie with its field-level diff and the snapshot it produced
+// @Tags         movies
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Movie ID"
+// @Param        rev  path      int     true  "Revision number"
+// @Success      200  {object}  utils.SuccessResponse{data=models.MovieRevision} "Movie revision fetched successfully"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      404  {object}  utils.ErrorResponse "Movie revision not found"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to fetch movie revision"
+// @Router       /api/movies/{id}/history/{rev} [get]
+func (h *Handler) GetMovieRevision(ctx *fiber.Ctx) error {
+	// fetch the revision
+	revision, err := h.findRevision(ctx)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Movie revision not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch movie revision", err.Error())
+	}
+
+	// return success response with revision data
+	return utils.OKResponse(ctx, "Movie revision fetched successfully", revision)
+}
+
+// RevertMovie godoc
+// @Summary      Revert a movie
+// @Description  roll a movie back to the snapshot of an earlier revision, which is recorded as a new revision
+// @Tags         movies
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id        path      string  true   "Movie ID"
+// @Param        rev       path      int     true   "Revision number to roll back to"
+// @Param        If-Match  header    string  false  "ETag of the movie being reverted"
+// @Success      200  {object}  utils.SuccessResponse{data=models.Movie} "Movie reverted successfully"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      404  {object}  utils.ErrorResponse "Movie or revision not found"
+// @Failure      409  {object}  utils.ErrorResponse "Revision references genres that no longer exist"
+// @Failure      412  {object}  utils.ErrorResponse "Movie has been modified"
+// @Failure      428  {object}  utils.ErrorResponse "If-Match header is required"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to revert movie"
+// @Router       /api/movies/{id}/revert/{rev} [post]
+func (h *Handler) RevertMovie(ctx *fiber.Ctx) error {
+	// fetch the existing movie and its genres
+	movie, err := h.Movies.Get(ctx.UserContext(), paramID(ctx, "id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Movie not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to revert movie", err.Error())
+	}
+
+	// make sure the client reverts the version it has seen
+	if !ifMatch(ctx, movie) {
+		return utils.PreconditionFailedResponse(ctx, "Movie has been modified", errMovieModified.Error())
+	}
+
+	// fetch the revision to roll back to
+	revision, err := h.findRevision(ctx)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Movie revision not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to revert movie", err.Error())
+	}
+
+	// decode the snapshot of the revision
+	snapshot := new(models.Movie)
+	if err := sonic.Unmarshal(revision.Snapshot, snapshot); err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to revert movie", err.Error())
+	}
+
+	// resolve the genres of the snapshot, some may have been deleted since
+	genres, invalid, err := h.resolveGenres(ctx.UserContext(), snapshot.Genres)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to resolve genres", err.Error())
+	}
+	if invalid != nil {
+		return utils.ConflictResponse(ctx, "Revision references genres that no longer exist", invalid)
+	}
+
+	// remember the state being replaced
+	previous := movieDocument(movie)
+
+	// restore the fields of the snapshot
+	movie.Title = snapshot.Title
+	movie.Description = snapshot.Description
+	movie.PosterURL = snapshot.PosterURL
+	movie.ReleaseDate = snapshot.ReleaseDate
+	movie.Rating = snapshot.Rating
+	movie.DurationMinutes = snapshot.DurationMinutes
+	movie.Director = snapshot.Director
+	movie.Genres = genres
+
+	// update the movie with its genres and director credit, and its history
+	err = h.Tx.WithinTransaction(ctx.UserContext(), func(tx context.Context) error {
+		if err := h.Movies.Update(tx, movie); err != nil {
+			return err
+		}
+		return h.recordRevision(ctx, tx, models.RevisionRevert, movie, previous)
+	})
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		return utils.PreconditionFailedResponse(ctx, "Movie has been modified", errMovieModified.Error())
+	}
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to revert movie", err.Error())
+	}
+
+	// return success response
+	ctx.Set(fiber.HeaderETag, movie.ETag())
+	return utils.OKResponse(ctx, "Movie Reverted successfully", movie)
+}
+
+// findRevision fetches the revision named by the id and rev URL parameters.
+func (h *Handler) findRevision(ctx *fiber.Ctx) (*models.MovieRevision, error) {
+	return h.Revisions.Get(ctx.UserContext(), paramID(ctx, "id"), paramID(ctx, "rev"))
+}
+
+// recordRevision writes the audit entry for a change that left the movie at
+// its current version, in the transaction of tx. previous is the document
+// before the change, nil for a new or restored movie. Deletes and purges
+// diff every field to null, their snapshot is the movie as deleted.
+func (h *Handler) recordRevision(ctx *fiber.Ctx, tx context.Context, action string, movie *models.Movie, previous map[string]interface{}) error {
+	return h.writeRevision(tx, auditActor(ctx), middlewares.RequestID(ctx), action, movie, previous)
+}
+
+// writeRevision writes an audit entry like recordRevision, for changes made
+// past their request.
+func (h *Handler) writeRevision(tx context.Context, actor, requestID, action string, movie *models.Movie, previous map[string]interface{}) error {
+	current := movieDocument(movie)
+
+	// a deleted movie has no fields left
+	changed := current
+	if action == models.RevisionDelete || action == models.RevisionPurge {
+		changed = nil
+	}
+
+	diff, err := sonic.Marshal(diffDocuments(previous, changed))
+	if err != nil {
+		return err
+	}
+	snapshot, err := sonic.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	return h.Revisions.Create(tx, &models.MovieRevision{
+		MovieID:   movie.ID,
+		Revision:  movie.Version,
+		Action:    action,
+		Actor:     actor,
+		RequestID: requestID,
+		Diff:      diff,
+		Snapshot:  snapshot,
+	})
+}
+
+// diffDocuments returns the fields whose value differs between two movie
+// documents, fields missing from one of them changing from or to null.
+func diffDocuments(previous, current map[string]interface{}) map[string]models.FieldChange {
+	diff := make(map[string]models.FieldChange)
+	for field, value := range current {
+		if previousValue, ok := previous[field]; !ok || !reflect.DeepEqual(previousValue, value) {
+			diff[field] = models.FieldChange{From: previous[field], To: value}
+		}
+	}
+	for field, value := range previous {
+		if _, ok := current[field]; !ok {
+			diff[field] = models.FieldChange{From: value, To: nil}
+		}
+	}
+	return diff
+}
+
+// auditActor names who made the request in the audit history, falling back
+// to the client address for anonymous requests.
+func auditActor(ctx *fiber.Ctx) string {
+	if claims := middlewares.CurrentUser(ctx); claims != nil {
+		return "user:" + claims.Subject
+	}
+	if key := middlewares.CurrentAPIKey(ctx); key != nil {
+		return "api_key:" + key.Prefix
+	}
+	return "anonymous@" + ctx.IP()
+}
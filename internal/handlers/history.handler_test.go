@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+func TestMovieHistory(t *testing.T) {
+	runBackends(t, func(t *testing.T, app *testApp) {
+		movie := createMovies(t, app)["Alien"]
+		path := "/api/movies/" + strconv.Itoa(int(movie.ID))
+
+		res := app.do(t, fiber.MethodPut, path, newMovieBody("Alien: Director's Cut", "1979-05-25", 8.5, 116, "Ridley Scott", "science-fiction", "drama"),
+			fiber.HeaderIfMatch, movie.ETag()).expect(t, fiber.StatusOK)
+		updated := decode[models.Movie](t, res.body.Data)
+
+		// revisions are listed newest first, with the fields each one changed
+		res = app.do(t, fiber.MethodGet, path+"/history", nil).expect(t, fiber.StatusOK)
+		revisions := decode[[]models.MovieRevision](t, res.body.Data)
+		if len(revisions) != 2 || revisions[0].Revision != 2 || revisions[0].Action != models.RevisionUpdate || revisions[1].Action != models.RevisionCreate {
+			t.Fatalf("history = %+v, want the update then the creation", revisions)
+		}
+		diff := decode[map[string]models.FieldChange](t, json.RawMessage(revisions[0].Diff))
+		if _, ok := diff["rating"]; ok || diff["title"].From != "Alien" || diff["title"].To != "Alien: Director's Cut" || diff["duration_minutes"].To != 116.0 {
+			t.Errorf("update diff = %v, want the changed title and duration only", diff)
+		}
+		if pagination := decode[utils.Pagination](t, res.body.Meta); pagination.Total != 2 {
+			t.Errorf("history total = %d, want 2", pagination.Total)
+		}
+
+		res = app.do(t, fiber.MethodGet, path+"/history/1", nil).expect(t, fiber.StatusOK)
+		created := decode[models.MovieRevision](t, res.body.Data)
+		if snapshot := decode[map[string]interface{}](t, json.RawMessage(created.Snapshot)); created.Action != models.RevisionCreate || snapshot["title"] != "Alien" {
+			t.Errorf("revision 1 = %s with snapshot %v, want the creation of Alien", created.Action, snapshot)
+		}
+		app.do(t, fiber.MethodGet, path+"/history/9", nil).expect(t, fiber.StatusNotFound)
+		app.do(t, fiber.MethodGet, "/api/movies/999/history", nil).expect(t, fiber.StatusNotFound)
+
+		// reverting needs the current version and records a new revision
+		app.do(t, fiber.MethodPost, path+"/revert/1", nil, fiber.HeaderIfMatch, movie.ETag()).
+			expect(t, fiber.StatusPreconditionFailed)
+		res = app.do(t, fiber.MethodPost, path+"/revert/1", nil, fiber.HeaderIfMatch, updated.ETag()).expect(t, fiber.StatusOK)
+		reverted := decode[models.Movie](t, res.body.Data)
+		if reverted.Title != "Alien" || reverted.DurationMinutes != 117 || len(reverted.Genres) != 1 || reverted.Version != 3 {
+			t.Errorf("reverted movie = %+v, want Alien at version 3 as created", reverted)
+		}
+
+		res = app.do(t, fiber.MethodGet, path+"/history?per_page=1", nil).expect(t, fiber.StatusOK)
+		revisions = decode[[]models.MovieRevision](t, res.body.Data)
+		if len(revisions) != 1 || revisions[0].Revision != 3 || revisions[0].Action != models.RevisionRevert {
+			t.Errorf("latest revision = %+v, want the revert", revisions)
+		}
+		if pagination := decode[utils.Pagination](t, res.body.Meta); pagination.Total != 3 || pagination.TotalPages != 3 {
+			t.Errorf("history pagination = %+v, want 3 revisions", pagination)
+		}
+		app.do(t, fiber.MethodPost, path+"/revert/7", nil).expect(t, fiber.StatusNotFound)
+	})
+}
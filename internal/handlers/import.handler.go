@@ -0,0 +1,483 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/middlewares"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+	"gorm.io/datatypes"
+)
+
+// importColumns lists the columns of CSV imports, named as the JSON fields of a movie.
+var importColumns = []string{"title", "description", "poster_url", "release_date", "rating", "duration_minutes", "director", "genres"}
+
+// importProgressRows is the number of rows between the progress updates of background imports.
+const importProgressRows = 100
+
+// importRecord is a row of an import file, with the errors that kept it from being parsed.
+type importRecord struct {
+	row    int
+	movie  models.Movie
+	errors []string
+}
+
+// ImportMovies godoc
+// @Summary      Import movies
+// @Description  create or update movies in bulk from a CSV file, a JSON array or an NDJSON stream, validated like single movies.
+// @Description  CSV files start with a header naming the columns title, description, poster_url, release_date, rating, duration_minutes, director and genres, genres being separated by "|".
+// @Description  Movies are matched on their title and release date, existing ones fail unless upsert is set. Dry runs report the outcome without writing.
+// @Description  Imports of more rows than IMPORT_SYNC_ROWS run in the background, poll the job at the Location header until it is no longer running.
+// @Tags         movies
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        file     formData  file    true   "Movies file"
+// @Param        format   query     string  false  "File format, guessed from the file name or content type by default"  Enums(csv, json, ndjson)
+// @Param        dry_run  query     bool    false  "Report the outcome without writing any movie"
+// @Param        upsert   query     bool    false  "Update the movies already present instead of failing their rows"
+// @Success      200  {object}  utils.SuccessResponse{data=models.ImportJob} "Movies imported successfully"
+// @Success      202  {object}  utils.SuccessResponse{data=models.ImportJob} "Movie import started"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid query parameters or import file"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      415  {object}  utils.ErrorResponse "Unsupported import format"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to import movies"
+// @Router       /api/movies/import [post]
+func (h *Handler) ImportMovies(ctx *fiber.Ctx) error {
+	// parse the query parameters
+	query := new(queries.MovieImportQuery)
+	if err := ctx.QueryParser(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid query parameters", err.Error())
+	}
+
+	// validate the query parameters
+	if err := validators.ValidateStruct(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// fetch the uploaded file and work out its format
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid import file", err.Error())
+	}
+	format := query.Format
+	if format == "" {
+		format = importFormat(file)
+	}
+	if format == "" {
+		return utils.UnsupportedMediaTypeResponse(ctx, "Unsupported import format", "Send a .csv, .json or .ndjson file, or set the format parameter")
+	}
+
+	// parse every row before importing any, a malformed file imports nothing
+	content, err := file.Open()
+	if err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid import file", err.Error())
+	}
+	defer content.Close()
+	records, err := parseImport(format, content)
+	if err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid import file", err.Error())
+	}
+	if len(records) == 0 {
+		return utils.BadRequestResponse(ctx, "Invalid import file", "The file holds no movies")
+	}
+
+	// record the job, the request is audited as the author of the changes
+	job := &models.ImportJob{
+		Status: models.ImportStatusRunning,
+		Format: format,
+		DryRun: query.DryRun,
+		Upsert: query.Upsert,
+		Actor:  auditActor(ctx),
+		Total:  len(records),
+		Report: datatypes.JSONSlice[models.ImportRow]{},
+	}
+	if err := h.ImportJobs.Create(ctx.UserContext(), job); err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to import movies", err.Error())
+	}
+	requestID := middlewares.RequestID(ctx)
+
+	// small imports complete within the request
+	if len(records) <= h.ImportSyncRows {
+		if err := h.runImport(ctx.UserContext(), job, records, requestID); err != nil {
+			return utils.InternalServerErrorResponse(ctx, "Failed to import movies", err.Error())
+		}
+		return utils.OKResponse(ctx, "Movies imported successfully", job)
+	}
+
+	// large ones continue in the background until shutdown, with copies of
+	// the strings fiber reuses once the request is over
+	background := *job
+	background.Format, background.Actor = strings.Clone(job.Format), strings.Clone(job.Actor)
+	requestID = strings.Clone(requestID)
+	h.imports.wg.Add(1)
+	go func() {
+		defer h.imports.wg.Done()
+		if err := h.runImport(h.imports.ctx, &background, records, requestID); err != nil {
+			log.Error().Err(err).Uint("import_id", background.ID).Msg("Failed to record movie import")
+		}
+	}()
+
+	// return accepted response pointing at the job
+	ctx.Location(fmt.Sprintf("/api/movies/import/%d", job.ID))
+	return utils.AcceptedResponse(ctx, "Movie import started", job)
+}
+
+// GetImportJob godoc
+// @Summary      Get a movie import
+// @Description  get the progress of a movie import by ID, and the report of its rows once finished
+// @Tags         movies
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id  path      string  true  "Import ID"
+// @Success      200  {object}  utils.SuccessResponse{data=models.ImportJob} "Import fetched successfully"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      404  {object}  utils.ErrorResponse "Import not found"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to fetch import"
+// @Router       /api/movies/import/{id} [get]
+func (h *Handler) GetImportJob(ctx *fiber.Ctx) error {
+	// fetch the import job and its report
+	job, err := h.ImportJobs.Get(ctx.UserContext(), paramID(ctx, "id"))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return utils.NotFoundResponse(ctx, "Import not found", err.Error())
+	}
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch import", err.Error())
+	}
+
+	// return success response with import data
+	return utils.OKResponse(ctx, "Import fetched successfully", job)
+}
+
+// runImport imports the rows one at a time and records their outcome in the
+// job, saving the counters of background imports as they progress. An import
+// cancelled by shutdown fails, keeping the report of the rows it processed.
+func (h *Handler) runImport(ctx context.Context, job *models.ImportJob, records []importRecord, requestID string) error {
+	// natural keys of the movies a dry run would have created
+	planned := make(map[string]bool)
+
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			job.Status = models.ImportStatusFailed
+			job.Error = fmt.Sprintf("Import interrupted after %d of %d rows: %v", job.Processed, job.Total, err)
+			break
+		}
+
+		row := h.importRow(ctx, job, record, requestID, planned)
+		job.Report = append(job.Report, row)
+		job.Processed++
+		switch row.Status {
+		case models.ImportRowCreated:
+			job.Created++
+		case models.ImportRowUpdated:
+			job.Updated++
+		default:
+			job.Failed++
+		}
+
+		if job.Processed%importProgressRows == 0 {
+			if err := h.ImportJobs.SaveProgress(ctx, job); err != nil && ctx.Err() == nil {
+				log.Warn().Err(err).Uint("import_id", job.ID).Msg("Failed to record movie import progress")
+			}
+		}
+	}
+
+	// record the outcome, even past the cancellation of the import
+	if job.Status == models.ImportStatusRunning {
+		job.Status = models.ImportStatusCompleted
+	}
+	now := time.Now()
+	job.FinishedAt = &now
+	return h.ImportJobs.Save(context.WithoutCancel(ctx), job)
+}
+
+// importRow creates or updates the movie of a row in its own transaction,
+// like CreateMovie and UpdateMovie do, and returns the outcome of the row.
+func (h *Handler) importRow(ctx context.Context, job *models.ImportJob, record importRecord, requestID string, planned map[string]bool) models.ImportRow {
+	row := models.ImportRow{Row: record.row, Title: record.movie.Title}
+	fail := func(errs ...string) models.ImportRow {
+		row.Status = models.ImportRowFailed
+		row.Errors = errs
+		return row
+	}
+
+	// validate the movie and resolve its genres
+	if record.errors != nil {
+		return fail(record.errors...)
+	}
+	if errs := validators.ValidateStruct(&record.movie); errs != nil {
+		return fail(errs...)
+	}
+	genres, invalid, err := h.resolveGenres(ctx, record.movie.Genres)
+	if err != nil {
+		return fail("Failed to resolve genres: " + err.Error())
+	}
+	if invalid != nil {
+		return fail(invalid...)
+	}
+
+	// match the movie on its natural key, including the ones earlier rows of a dry run would have created
+	title, releaseDate := record.movie.Title, record.movie.ReleaseDate
+	existing, err := h.Movies.GetByTitle(ctx, title, releaseDate)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return fail("Failed to fetch movie: " + err.Error())
+	}
+	key := title + "\x00" + releaseDate
+	if existing != nil {
+		row.MovieID = existing.ID
+	}
+	if (existing != nil || planned[key]) && !job.Upsert {
+		return fail(fmt.Sprintf("Movie %q released on %s already exists", title, releaseDate))
+	}
+
+	// dry runs stop short of writing
+	if job.DryRun {
+		row.Status = models.ImportRowCreated
+		if existing != nil || planned[key] {
+			row.Status = models.ImportRowUpdated
+		}
+		planned[key] = true
+		return row
+	}
+
+	// apply the row to the existing movie, or to a new one
+	movie, action := existing, models.RevisionUpdate
+	var previous map[string]interface{}
+	if movie == nil {
+		movie, action = new(models.Movie), models.RevisionCreate
+	} else {
+		previous = movieDocument(movie)
+	}
+	movie.Title = record.movie.Title
+	movie.Description = record.movie.Description
+	movie.PosterURL = record.movie.PosterURL
+	movie.ReleaseDate = record.movie.ReleaseDate
+	movie.Rating = record.movie.Rating
+	movie.DurationMinutes = record.movie.DurationMinutes
+	movie.Director = record.movie.Director
+	movie.Genres = genres
+
+	// write the movie with its genres and director credit, and its history
+	err = h.Tx.WithinTransaction(ctx, func(tx context.Context) error {
+		if action == models.RevisionCreate {
+			if err := h.Movies.Create(tx, movie); err != nil {
+				return err
+			}
+		} else if err := h.Movies.Update(tx, movie); err != nil {
+			return err
+		}
+		return h.writeRevision(tx, job.Actor, requestID, action, movie, previous)
+	})
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		return fail("Movie was modified during the import")
+	}
+	if err != nil {
+		return fail("Failed to save movie: " + err.Error())
+	}
+
+	row.MovieID = movie.ID
+	row.Status = models.ImportRowCreated
+	if action == models.RevisionUpdate {
+		row.Status = models.ImportRowUpdated
+	}
+	return row
+}
+
+// importFormat guesses the format of an uploaded file from its name, then
+// from its content type. It is empty when neither is known.
+func importFormat(file *multipart.FileHeader) string {
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv":
+		return models.ImportFormatCSV
+	case ".json":
+		return models.ImportFormatJSON
+	case ".ndjson", ".jsonl":
+		return models.ImportFormatNDJSON
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(file.Header.Get(fiber.HeaderContentType))
+	switch mediaType {
+	case "text/csv":
+		return models.ImportFormatCSV
+	case fiber.MIMEApplicationJSON:
+		return models.ImportFormatJSON
+	case "application/x-ndjson", "application/jsonl":
+		return models.ImportFormatNDJSON
+	}
+	return ""
+}
+
+// parseImport reads the rows of an import file. Rows that cannot be read as
+// a movie carry their errors, files that cannot be read at all fail.
+func parseImport(format string, r io.Reader) ([]importRecord, error) {
+	switch format {
+	case models.ImportFormatCSV:
+		return parseCSV(r)
+	case models.ImportFormatJSON:
+		return parseJSONArray(r)
+	default:
+		return parseNDJSON(r)
+	}
+}
+
+func parseCSV(r io.Reader) ([]importRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	// map the header to movie fields, spreadsheets may prefix it with a byte order mark
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("the file has no header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(importColumns, name) {
+			return nil, fmt.Errorf("unknown column %q, expected some of %s", name, strings.Join(importColumns, ", "))
+		}
+		columns[i] = name
+	}
+
+	var records []importRecord
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		record := importRecord{row: line}
+		if len(fields) > len(columns) {
+			record.errors = append(record.errors, fmt.Sprintf("Row has %d fields, the header %d", len(fields), len(columns)))
+		}
+		for i, value := range fields[:min(len(fields), len(columns))] {
+			setCSVField(&record, columns[i], strings.TrimSpace(value))
+		}
+		records = append(records, record)
+	}
+}
+
+// setCSVField sets the movie field of a CSV column. Genres are separated by
+// "|" and referenced by ID, slug or name.
+func setCSVField(record *importRecord, column, value string) {
+	movie := &record.movie
+	switch column {
+	case "title":
+		movie.Title = value
+	case "description":
+		movie.Description = value
+	case "poster_url":
+		movie.PosterURL = value
+	case "release_date":
+		movie.ReleaseDate = value
+	case "director":
+		movie.Director = value
+	case "rating":
+		if value == "" {
+			return
+		}
+		rating, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			record.errors = append(record.errors, "Rating: This field must be a numeric value")
+		}
+		movie.Rating = rating
+	case "duration_minutes":
+		if value == "" {
+			return
+		}
+		duration, err := strconv.Atoi(value)
+		if err != nil {
+			record.errors = append(record.errors, "DurationMinutes: This field must be a numeric value")
+		}
+		movie.DurationMinutes = duration
+	case "genres":
+		for _, ref := range strings.Split(value, "|") {
+			ref = strings.TrimSpace(ref)
+			if ref == "" {
+				continue
+			}
+			if id, err := strconv.ParseUint(ref, 10, 0); err == nil {
+				movie.Genres = append(movie.Genres, models.Genre{ID: uint(id)})
+			} else {
+				movie.Genres = append(movie.Genres, models.Genre{Slug: utils.Slugify(ref)})
+			}
+		}
+	}
+}
+
+func parseJSONArray(r io.Reader) ([]importRecord, error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, errors.New("expected a JSON array of movies")
+	}
+
+	var records []importRecord
+	for row := 1; decoder.More(); row++ {
+		var data json.RawMessage
+		if err := decoder.Decode(&data); err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		records = append(records, decodeImportRecord(row, data))
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func parseNDJSON(r io.Reader) ([]importRecord, error) {
+	reader := bufio.NewReader(r)
+
+	var records []importRecord
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			records = append(records, decodeImportRecord(line, data))
+		}
+		if err != nil {
+			return records, nil
+		}
+	}
+}
+
+// decodeImportRecord decodes a movie of a JSON import, like the body of CreateMovie.
+func decodeImportRecord(row int, data []byte) importRecord {
+	record := importRecord{row: row}
+	if err := json.Unmarshal(data, &record.movie); err != nil {
+		record.errors = []string{"Invalid movie: " + err.Error()}
+	}
+	return record
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+)
+
+// testImportCSV holds two valid movies, one with a genre referenced by name,
+// and a row with more fields than the header, behind a byte order mark.
+const testImportCSV = "\ufefftitle,description,poster_url,release_date,rating,duration_minutes,director,genres\n" +
+	"Heat,A heist film,https://example.com/heat.jpg,1995-12-15,8.3,170,Michael Mann,crime\n" +
+	"Alien,A space horror film,https://example.com/alien.jpg,1979-05-25,8.5,117,Ridley Scott,science-fiction|Drama\n" +
+	"Ronin,A heist film,https://example.com/ronin.jpg,1998-09-25,7.2,122,John Frankenheimer,crime,thriller\n"
+
+// testImportNDJSON holds a new movie and one already imported from testImportCSV.
+const testImportNDJSON = `{"title":"The Godfather","description":"A crime saga","poster_url":"https://example.com/godfather.jpg","release_date":"1972-03-24","rating":9.2,"duration_minutes":175,"director":"Francis Ford Coppola","genres":[{"slug":"crime"}]}
+
+{"title":"Heat","description":"A heist film, remastered","poster_url":"https://example.com/heat.jpg","release_date":"1995-12-15","rating":8.4,"duration_minutes":170,"director":"Michael Mann","genres":[{"slug":"crime"},{"slug":"drama"}]}
+`
+
+// upload posts a file as the multipart form of an import.
+func (a *testApp) upload(t *testing.T, target, filename, content string) *testResponse {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(fiber.MethodPost, target, &body)
+	req.Header.Set(fiber.HeaderContentType, form.FormDataContentType())
+	return a.send(t, req)
+}
+
+// importStatuses returns the status of every row of an import report.
+func importStatuses(job models.ImportJob) []string {
+	statuses := make([]string, len(job.Report))
+	for i, row := range job.Report {
+		statuses[i] = row.Status
+	}
+	return statuses
+}
+
+func TestImportMovies(t *testing.T) {
+	runBackends(t, func(t *testing.T, app *testApp) {
+		// a dry run reports the outcome without writing
+		res := app.upload(t, "/api/movies/import?dry_run=true", "movies.csv", testImportCSV).expect(t, fiber.StatusOK)
+		job := decode[models.ImportJob](t, res.body.Data)
+		if job.Status != models.ImportStatusCompleted || job.Format != models.ImportFormatCSV || job.Created != 2 || job.Failed != 1 {
+			t.Fatalf("dry run = %+v, want two movies created and one row failed", job)
+		}
+		if ronin := job.Report[2]; ronin.Row != 4 || len(ronin.Errors) != 1 || ronin.Errors[0] != "Row has 9 fields, the header 8" {
+			t.Errorf("extra field row = %+v, want row 4 failed on its field count", ronin)
+		}
+		res = app.do(t, fiber.MethodGet, "/api/movies", nil).expect(t, fiber.StatusOK)
+		if got := titles(t, res); len(got) != 0 {
+			t.Errorf("movies after the dry run = %v, want none", got)
+		}
+
+		// rows of a dry run see the movies the earlier rows would have created
+		res = app.upload(t, "/api/movies/import?dry_run=true&upsert=true", "movies.csv", testImportCSV+
+			"Heat,A heist film,https://example.com/heat.jpg,1995-12-15,8.3,170,Michael Mann,crime\n").expect(t, fiber.StatusOK)
+		job = decode[models.ImportJob](t, res.body.Data)
+		if got := importStatuses(job); job.Created != 2 || job.Updated != 1 || got[3] != models.ImportRowUpdated {
+			t.Errorf("planned dry run = %v, want the second Heat updated", got)
+		}
+
+		// the CSV import creates the movies of the valid rows
+		res = app.upload(t, "/api/movies/import", "movies.csv", testImportCSV).expect(t, fiber.StatusOK)
+		job = decode[models.ImportJob](t, res.body.Data)
+		if job.Created != 2 || job.Failed != 1 || job.Report[0].MovieID == 0 {
+			t.Fatalf("csv import = %+v, want two movies created", job)
+		}
+		res = app.do(t, fiber.MethodGet, "/api/movies/"+strconv.Itoa(int(job.Report[1].MovieID)), nil).expect(t, fiber.StatusOK)
+		if alien := decode[models.Movie](t, res.body.Data); alien.Title != "Alien" || len(alien.Genres) != 2 {
+			t.Errorf("imported movie = %+v, want Alien with two genres", alien)
+		}
+
+		// movies already present fail without upsert
+		res = app.upload(t, "/api/movies/import", "movies.ndjson", testImportNDJSON).expect(t, fiber.StatusOK)
+		job = decode[models.ImportJob](t, res.body.Data)
+		if got := importStatuses(job); job.Format != models.ImportFormatNDJSON || job.Created != 1 || job.Failed != 1 || got[1] != models.ImportRowFailed || job.Report[1].Row != 3 {
+			t.Errorf("ndjson import = %+v, want The Godfather created and Heat on line 3 failed", job)
+		}
+
+		// and are updated with it
+		res = app.upload(t, "/api/movies/import?upsert=true", "movies.ndjson", testImportNDJSON).expect(t, fiber.StatusOK)
+		job = decode[models.ImportJob](t, res.body.Data)
+		if job.Updated != 2 || job.Failed != 0 {
+			t.Errorf("upsert = %+v, want both movies updated", job)
+		}
+		res = app.do(t, fiber.MethodGet, "/api/movies/"+strconv.Itoa(int(job.Report[1].MovieID)), nil).expect(t, fiber.StatusOK)
+		if heat := decode[models.Movie](t, res.body.Data); heat.Rating != 8.4 || heat.Version != 2 {
+			t.Errorf("upserted movie = %+v, want Heat rated 8.4 at version 2", heat)
+		}
+
+		// malformed files import nothing
+		app.upload(t, "/api/movies/import", "movies.csv", "title,budget\nHeat,60000000\n").expect(t, fiber.StatusBadRequest)
+		app.upload(t, "/api/movies/import", "movies.json", `{"title":"Heat"}`).expect(t, fiber.StatusBadRequest)
+		app.upload(t, "/api/movies/import", "movies.csv", "").expect(t, fiber.StatusBadRequest)
+		app.upload(t, "/api/movies/import", "movies.txt", testImportCSV).expect(t, fiber.StatusUnsupportedMediaType)
+	})
+}
+
+func TestImportMoviesInBackground(t *testing.T) {
+	runBackends(t, func(t *testing.T, app *testApp) {
+		app.h.ImportSyncRows = 1
+
+		// imports of more rows than the limit are accepted and run past their request
+		res := app.upload(t, "/api/movies/import?format=ndjson", "movies", testImportNDJSON).expect(t, fiber.StatusAccepted)
+		job := decode[models.ImportJob](t, res.body.Data)
+		if job.Status != models.ImportStatusRunning || res.header.Get(fiber.HeaderLocation) != "/api/movies/import/"+strconv.Itoa(int(job.ID)) {
+			t.Fatalf("import = %+v at %q, want it running at its job", job, res.header.Get(fiber.HeaderLocation))
+		}
+		app.h.imports.wg.Wait()
+
+		res = app.do(t, fiber.MethodGet, res.header.Get(fiber.HeaderLocation), nil).expect(t, fiber.StatusOK)
+		job = decode[models.ImportJob](t, res.body.Data)
+		if job.Status != models.ImportStatusCompleted || job.Processed != 2 || job.Created != 2 || job.FinishedAt == nil {
+			t.Errorf("finished import = %+v, want both movies created", job)
+		}
+		app.do(t, fiber.MethodGet, "/api/movies/import/999", nil).expect(t, fiber.StatusNotFound)
+	})
+}
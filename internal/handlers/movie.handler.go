@@ -1,43 +1,129 @@
 package handlers
 
 import (
+	"context"
 	"errors"
+	"slices"
 
-	"github.com/bytedance/sonic"
 	"github.com/gofiber/fiber/v2"
-	"github.com/zdacoder/go-fiber-movie-app-api/config/database"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/middlewares"
 	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
 	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
 	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
-	"gorm.io/gorm"
 )
 
 // ListMovies godoc
 // @Summary      List movies
-// @Description  get list of all movies
+// @Description  get a paginated, sorted and filtered list of movies
 // @Tags         movies
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  utils.SuccessResponse "Movies fetched successfully"
+// @Param        page          query     int     false  "Page number"  minimum(1)
+// @Param        per_page      query     int     false  "Movies per page"  minimum(1)  maximum(100)
+// @Param        limit         query     int     false  "Alternative to per_page"  minimum(1)  maximum(100)
+// @Param        offset        query     int     false  "Alternative to page"  minimum(0)
+// @Param        cursor        query     string  false  "Opaque cursor for keyset pagination, send it empty for the first page (meta becomes utils.CursorPagination)"
+// @Param        sort          query     string  false  "Sort field"  Enums(title, release_date, rating, duration_minutes, created_at)
+// @Param        order         query     string  false  "Sort direction"  Enums(asc, desc)
+// @Param        director      query     string  false  "Director name (partial match)"
+// @Param        genre         query     string  false  "Genre slug or name"
+// @Param        year_from     query     int     false  "Minimum release year"
+// @Param        year_to       query     int     false  "Maximum release year"
+// @Param        rating_min    query     number  false  "Minimum rating"
+// @Param        rating_max    query     number  false  "Maximum rating"
+// @Param        duration_min  query     int     false  "Minimum duration in minutes"
+// @Param        duration_max  query     int     false  "Maximum duration in minutes"
+// @Success      200  {object}  utils.SuccessResponse{meta=utils.Pagination} "Movies fetched successfully"
 // @Failure      204	{object}  utils.ErrorResponse "Movies data is empty"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid query parameters"
 // @Failure      500  {object}  utils.ErrorResponse "Failed to fetch movies"
 // @Router       /api/movies [get]
-func ListMovies(ctx *fiber.Ctx) error {
-	// initialize a slice to hold movies
-	var movies []models.Movie
+func (h *Handler) ListMovies(ctx *fiber.Ctx) error {
+	// listings tolerate replication lag
+	ctx.SetUserContext(repositories.FromReplica(ctx.UserContext()))
 
-	// fetch all movies from the database
-	if err := database.DB.Order("created_at desc").Find(&movies).Error; err != nil {
+	// parse the query parameters
+	query := new(queries.MovieListQuery)
+	if err := ctx.QueryParser(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid query parameters", err.Error())
+	}
+
+	// validate the query parameters
+	if err := validators.ValidateStruct(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	return h.listMovies(ctx, query)
+}
+
+// listMovies returns a page of movies matching the query, using keyset
+// pagination when a cursor parameter is present.
+func (h *Handler) listMovies(ctx *fiber.Ctx, query *queries.MovieListQuery) error {
+	if ctx.Request().URI().QueryArgs().Has("cursor") {
+		return h.listMoviesByCursor(ctx, query)
+	}
+
+	// fetch the requested page of movies and count the movies matching the filters
+	movies, total, err := h.Movies.List(ctx.UserContext(), query)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch movies", err.Error())
+	}
+
+	// check if movies slice is empty
+	if len(movies) == 0 {
+		return utils.NoContentResponse(ctx, "Movies data is empty")
+	}
+
+	// return success response with movies data and pagination metadata
+	pagination := utils.NewPagination(ctx, query.CurrentPage(), query.Size(), total)
+	return utils.PaginatedResponse(ctx, "Movies fetched successfully", movies, pagination)
+}
+
+// listMoviesByCursor returns a page of movies located with keyset pagination,
+// which stays stable while movies are inserted.
+func (h *Handler) listMoviesByCursor(ctx *fiber.Ctx, query *queries.MovieListQuery) error {
+	// verify and decode the cursor
+	position, err := query.DecodeCursor()
+	if err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid cursor", err.Error())
+	}
+
+	// fetch the movies after the cursor position
+	movies, err := h.Movies.ListByCursor(ctx.UserContext(), query, position)
+	if err != nil {
 		return utils.InternalServerErrorResponse(ctx, "Failed to fetch movies", err.Error())
 	}
 
+	// the extra row tells whether there is another page in the direction of travel
+	backward := position != nil && position.Backward
+	hasMore := len(movies) > query.Size()
+	if hasMore {
+		movies = movies[:query.Size()]
+	}
+
+	// backward pages are fetched in reverse order
+	if backward {
+		slices.Reverse(movies)
+	}
+
 	// check if movies slice is empty
 	if len(movies) == 0 {
 		return utils.NoContentResponse(ctx, "Movies data is empty")
 	}
 
-	// return success response with movies data
-	return utils.OKResponse(ctx, "Movies fetched successfully", movies)
+	// build the cursors pointing at both ends of the page
+	pagination := utils.CursorPagination{PerPage: query.Size()}
+	if hasMore || backward {
+		pagination.NextCursor = query.CursorFor(&movies[len(movies)-1], false)
+	}
+	if (backward && hasMore) || (!backward && position != nil) {
+		pagination.PrevCursor = query.CursorFor(&movies[0], true)
+	}
+
+	// return success response with movies data and cursors
+	return utils.PaginatedResponse(ctx, "Movies fetched successfully", movies, pagination)
 }
 
 // GetMovie godoc
@@ -46,26 +132,32 @@ func ListMovies(ctx *fiber.Ctx) error {
 // @Tags         movies
 // @Accept       json
 // @Produce      json
-// @Param        id   path      string  true  "Movie ID"
+// @Param        id             path      string  true   "Movie ID"
+// @Param        If-None-Match  header    string  false  "ETag of a cached representation"
 // @Success      200  {object}  utils.SuccessResponse "Movie fetched successfully"
+// @Success      304  "Movie not modified"
 // @Failure      404  {object}  utils.ErrorResponse "Movie not found"
 // @Failure      500  {object}  utils.ErrorResponse "Failed to fetch movie"
 // @Router      /api/movies/{id} [get]
-func GetMovie(ctx *fiber.Ctx) error {
-	// get movie ID from URL parameters
-	id := ctx.Params("id")
-
-	// initialize a new movie instance
-	movie := new(models.Movie)
+func (h *Handler) GetMovie(ctx *fiber.Ctx) error {
+	// lookups tolerate replication lag, writes check the version on the primary
+	ctx.SetUserContext(repositories.FromReplica(ctx.UserContext()))
 
-	// fetch the movie from the database by ID
-	if err := database.DB.First(movie, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	// fetch the movie and its genres by ID
+	movie, err := h.Movies.Get(ctx.UserContext(), paramID(ctx, "id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
 			return utils.NotFoundResponse(ctx, "Movie not found", err.Error())
 		}
 		return utils.InternalServerErrorResponse(ctx, "Failed to fetch movie", err.Error())
 	}
 
+	// answer conditional requests for a cached representation
+	ctx.Set(fiber.HeaderETag, movie.ETag())
+	if header := ctx.Get(fiber.HeaderIfNoneMatch); header != "" && utils.MatchesETag(header, movie.ETag(), true) {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
 	// return success response with movie data
 	return utils.OKResponse(ctx, "Movie fetched successfully", movie)
 }
@@ -76,12 +168,16 @@ func GetMovie(ctx *fiber.Ctx) error {
 // @Tags         movies
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Param        movie  body      models.Movie  true  "Movie data"
 // @Success      201  {object}  utils.SuccessResponse "Movie created successfully"
 // @Failure      400  {object}  utils.ErrorResponse "Invalid request body or validation failed"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
 // @Failure      500  {object}  utils.ErrorResponse "Failed to create movie"
 // @Router       /api/movies [post]
-func CreateMovie(ctx *fiber.Ctx) error {
+func (h *Handler) CreateMovie(ctx *fiber.Ctx) error {
 	// initialize a new movie instance
 	movie := new(models.Movie)
 
@@ -95,21 +191,29 @@ func CreateMovie(ctx *fiber.Ctx) error {
 		return utils.BadRequestResponse(ctx, "Validation failed", err)
 	}
 
-	// marshal genre to JSON
-	genreJSON, err := sonic.Marshal(movie.Genre)
-	// handle marshaling error
+	// resolve the referenced genres
+	genres, invalid, err := h.resolveGenres(ctx.UserContext(), movie.Genres)
 	if err != nil {
-		return utils.BadRequestResponse(ctx, "Invalid genre format", err.Error())
+		return utils.InternalServerErrorResponse(ctx, "Failed to resolve genres", err.Error())
+	}
+	if invalid != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", invalid)
 	}
-	// assign marshaled genre back to movie
-	movie.Genre = genreJSON
+	movie.Genres = genres
 
-	// create the movie record in the database
-	if err := database.DB.Create(movie).Error; err != nil {
+	// create the movie with its genre links and director credit, and its first revision
+	err = h.Tx.WithinTransaction(ctx.UserContext(), func(tx context.Context) error {
+		if err := h.Movies.Create(tx, movie); err != nil {
+			return err
+		}
+		return h.recordRevision(ctx, tx, models.RevisionCreate, movie, nil)
+	})
+	if err != nil {
 		return utils.InternalServerErrorResponse(ctx, "Failed to create movie", err.Error())
 	}
 
 	// return success response
+	ctx.Set(fiber.HeaderETag, movie.ETag())
 	return utils.CreatedResponse(ctx, "Movie Created successfully", movie)
 }
 
@@ -119,24 +223,32 @@ func CreateMovie(ctx *fiber.Ctx) error {
 // @Tags         movies
 // @Accept       json
 // @Produce      json
-// @Param        id     path      string       true  "Movie ID"
-// @Param        movie  body      models.Movie  true  "Updated movie data"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id        path      string        true   "Movie ID"
+// @Param        If-Match  header    string        false  "ETag of the movie being updated"
+// @Param        movie     body      models.Movie  true   "Updated movie data"
 // @Success      200  {object}  utils.SuccessResponse "Movie updated successfully"
 // @Failure      400  {object}  utils.ErrorResponse "Invalid request body or validation failed"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
 // @Failure      404  {object}  utils.ErrorResponse "Movie not found"
+// @Failure      412  {object}  utils.ErrorResponse "Movie has been modified"
+// @Failure      428  {object}  utils.ErrorResponse "If-Match header is required"
 // @Failure      500  {object}  utils.ErrorResponse "Failed to update movie"
 // @Router      /api/movies/{id} [put]
-func UpdateMovie(ctx *fiber.Ctx) error {
-	// get movie ID from URL parameters
-	id := ctx.Params("id")
-	// initialize a new movie instance to hold the request data
-	var movie models.Movie
-
-	// fetch the existing movie from the database
-	if err := database.DB.First(&movie, id).Error; err != nil {
+func (h *Handler) UpdateMovie(ctx *fiber.Ctx) error {
+	// fetch the existing movie and its genres
+	movie, err := h.Movies.Get(ctx.UserContext(), paramID(ctx, "id"))
+	if err != nil {
 		return utils.NotFoundResponse(ctx, "Movie not found", err.Error())
 	}
 
+	// make sure the client updates the version it has seen
+	if !ifMatch(ctx, movie) {
+		return utils.PreconditionFailedResponse(ctx, "Movie has been modified", errMovieModified.Error())
+	}
+
 	// initialize a new movie instance to hold the updated data and parse the request body
 	req := new(models.Movie)
 	if err := ctx.BodyParser(req); err != nil {
@@ -148,7 +260,19 @@ func UpdateMovie(ctx *fiber.Ctx) error {
 		return utils.BadRequestResponse(ctx, "Validation failed", err)
 	}
 
-	// update the movie record in the database
+	// resolve the referenced genres
+	genres, invalid, err := h.resolveGenres(ctx.UserContext(), req.Genres)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to resolve genres", err.Error())
+	}
+	if invalid != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", invalid)
+	}
+
+	// remember the state being replaced
+	previous := movieDocument(movie)
+
+	// apply the updated data
 	movie.Title = req.Title
 	movie.Description = req.Description
 	movie.PosterURL = req.PosterURL
@@ -156,44 +280,93 @@ func UpdateMovie(ctx *fiber.Ctx) error {
 	movie.Rating = req.Rating
 	movie.DurationMinutes = req.DurationMinutes
 	movie.Director = req.Director
-	movie.Genre = req.Genre
+	movie.Genres = genres
 
-	// update the movie record in the database
-	if err := database.DB.Save(movie).Error; err != nil {
+	// update the movie with its genres and director credit, and its history
+	err = h.Tx.WithinTransaction(ctx.UserContext(), func(tx context.Context) error {
+		if err := h.Movies.Update(tx, movie); err != nil {
+			return err
+		}
+		return h.recordRevision(ctx, tx, models.RevisionUpdate, movie, previous)
+	})
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		return utils.PreconditionFailedResponse(ctx, "Movie has been modified", errMovieModified.Error())
+	}
+	if err != nil {
 		return utils.InternalServerErrorResponse(ctx, "Failed to update movie", err.Error())
 	}
 
 	// return success response
+	ctx.Set(fiber.HeaderETag, movie.ETag())
 	return utils.OKResponse(ctx, "Movie Updated successfully", movie)
 }
 
 // DeleteMovie godoc
 // @Summary      Delete a movie
-// @Description  delete an existing movie by ID
+// @Description  move an existing movie to the trash by ID, or delete it permanently with its genre links and credits, its history is kept either way
 // @Tags         movies
 // @Accept       json
 // @Produce      json
-// @Param        id   path      string  true  "Movie ID"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id         path      string  true   "Movie ID"
+// @Param        permanent  query     bool    false  "Delete the movie and its credits permanently, including from the trash (admin only)"
+// @Param        If-Match   header    string  false  "ETag of the movie being deleted"
 // @Success      200  {object}  utils.SuccessResponse "Movie deleted successfully"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
 // @Failure      404  {object}  utils.ErrorResponse "Movie not found"
+// @Failure      412  {object}  utils.ErrorResponse "Movie has been modified"
+// @Failure      428  {object}  utils.ErrorResponse "If-Match header is required"
 // @Failure      500  {object}  utils.ErrorResponse "Failed to delete movie"
 // @Router      /api/movies/{id} [delete]
-func DeleteMovie(ctx *fiber.Ctx) error {
-	// get movie ID from URL parameters
-	id := ctx.Params("id")
-	// initialize a new movie instance
-	movie := new(models.Movie)
+func (h *Handler) DeleteMovie(ctx *fiber.Ctx) error {
+	// permanent deletes also reach movies already in the trash and are reserved to admins
+	permanent := ctx.QueryBool("permanent")
+	if permanent && !middlewares.HasPermission(ctx, models.PermissionAdmin) {
+		return utils.ForbiddenResponse(ctx, "Insufficient permissions", "Permanent deletes require the admin permission")
+	}
+	var opts []repositories.Option
+	action := models.RevisionDelete
+	if permanent {
+		opts = append(opts, repositories.Permanently())
+		action = models.RevisionPurge
+	}
 
-	// fetch the existing movie from the database
-	if err := database.DB.First(movie, id).Error; err != nil {
+	// fetch the existing movie and its genres
+	movie, err := h.Movies.Get(ctx.UserContext(), paramID(ctx, "id"), opts...)
+	if err != nil {
 		return utils.NotFoundResponse(ctx, "Movie not found", err.Error())
 	}
 
-	// delete the movie record from the database
-	if err := database.DB.Delete(movie).Error; err != nil {
+	// make sure the client deletes the version it has seen
+	if !ifMatch(ctx, movie) {
+		return utils.PreconditionFailedResponse(ctx, "Movie has been modified", errMovieModified.Error())
+	}
+
+	// move the movie to the trash, or remove it, its genre links and its
+	// credits for good, the history is kept either way
+	err = h.Tx.WithinTransaction(ctx.UserContext(), func(tx context.Context) error {
+		if err := h.Movies.Delete(tx, movie, opts...); err != nil {
+			return err
+		}
+		return h.recordRevision(ctx, tx, action, movie, movieDocument(movie))
+	})
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		return utils.PreconditionFailedResponse(ctx, "Movie has been modified", errMovieModified.Error())
+	}
+	if err != nil {
 		return utils.InternalServerErrorResponse(ctx, "Failed to delete movie", err.Error())
 	}
 
 	// return success response
 	return utils.OKResponse(ctx, "Movie Deleted successfully", nil)
 }
+
+var errMovieModified = errors.New("the movie was modified since the ETag sent in If-Match")
+
+// ifMatch reports whether the If-Match precondition, when sent, holds for the movie.
+func ifMatch(ctx *fiber.Ctx, movie *models.Movie) bool {
+	header := ctx.Get(fiber.HeaderIfMatch)
+	return header == "" || utils.MatchesETag(header, movie.ETag(), false)
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+func TestListMovies(t *testing.T) {
+	runBackends(t, func(t *testing.T, app *testApp) {
+		createMovies(t, app)
+
+		// pages count every matching movie
+		res := app.do(t, fiber.MethodGet, "/api/movies?sort=title&per_page=2&page=2", nil).expect(t, fiber.StatusOK)
+		if got, want := titles(t, res), []string{"Heat", "The Godfather"}; !slices.Equal(got, want) {
+			t.Errorf("page 2 = %v, want %v", got, want)
+		}
+		pagination := decode[utils.Pagination](t, res.body.Meta)
+		if pagination.Total != 5 || pagination.TotalPages != 3 || pagination.Page != 2 {
+			t.Errorf("pagination = %+v, want 5 movies on 3 pages", pagination)
+		}
+
+		tests := []struct {
+			query string
+			want  []string
+		}{
+			{"genre=crime", []string{"Heat", "The Godfather", "The Godfather Part II"}},
+			{"genre=Science Fiction", []string{"Alien", "Blade Runner"}},
+			{"director=scott", []string{"Alien", "Blade Runner"}},
+			{"year_from=1975&year_to=1990", []string{"Alien", "Blade Runner"}},
+			{"rating_min=9", []string{"The Godfather", "The Godfather Part II"}},
+			{"rating_max=8.3", []string{"Blade Runner", "Heat"}},
+			{"duration_min=170&duration_max=180", []string{"Heat", "The Godfather"}},
+			{"genre=drama&director=coppola&order=desc", []string{"The Godfather Part II", "The Godfather"}},
+			{"sort=release_date&order=desc&per_page=2", []string{"Heat", "Blade Runner"}},
+			{"sort=rating&limit=2&offset=1", []string{"Heat", "Alien"}},
+		}
+		for _, tt := range tests {
+			query := url.Values{"sort": {"title"}}
+			parsed, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, values := range parsed {
+				query[key] = values
+			}
+
+			res := app.do(t, fiber.MethodGet, "/api/movies?"+query.Encode(), nil).expect(t, fiber.StatusOK)
+			if got := titles(t, res); !slices.Equal(got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.query, got, tt.want)
+			}
+		}
+
+		// genre pages filter like the genre parameter
+		res = app.do(t, fiber.MethodGet, "/api/genres/drama/movies?sort=title", nil).expect(t, fiber.StatusOK)
+		if got, want := titles(t, res), []string{"Blade Runner", "The Godfather", "The Godfather Part II"}; !slices.Equal(got, want) {
+			t.Errorf("drama movies = %v, want %v", got, want)
+		}
+		app.do(t, fiber.MethodGet, "/api/genres/western/movies", nil).expect(t, fiber.StatusNotFound)
+
+		// no match is an empty answer, invalid parameters are rejected
+		res = app.do(t, fiber.MethodGet, "/api/movies?director=nobody", nil).expect(t, fiber.StatusOK)
+		if res.body.Message != "Movies data is empty" {
+			t.Errorf("message = %q, want the empty movies", res.body.Message)
+		}
+		app.do(t, fiber.MethodGet, "/api/movies?sort=budget", nil).expect(t, fiber.StatusBadRequest)
+		app.do(t, fiber.MethodGet, "/api/movies?year_from=1990&year_to=1980", nil).expect(t, fiber.StatusBadRequest)
+	})
+}
+
+func TestListMoviesByCursor(t *testing.T) {
+	runBackends(t, func(t *testing.T, app *testApp) {
+		createMovies(t, app)
+
+		page := func(cursor string, want []string) utils.CursorPagination {
+			t.Helper()
+			res := app.do(t, fiber.MethodGet, "/api/movies?sort=title&per_page=2&cursor="+url.QueryEscape(cursor), nil).expect(t, fiber.StatusOK)
+			if got := titles(t, res); !slices.Equal(got, want) {
+				t.Errorf("page = %v, want %v", got, want)
+			}
+			return decode[utils.CursorPagination](t, res.body.Meta)
+		}
+
+		// an empty cursor starts at the first page
+		first := page("", []string{"Alien", "Blade Runner"})
+		if first.NextCursor == "" || first.PrevCursor != "" {
+			t.Fatalf("first page cursors = %+v, want only a next cursor", first)
+		}
+		second := page(first.NextCursor, []string{"Heat", "The Godfather"})
+		if second.NextCursor == "" || second.PrevCursor == "" {
+			t.Fatalf("second page cursors = %+v, want both cursors", second)
+		}
+		last := page(second.NextCursor, []string{"The Godfather Part II"})
+		if last.PrevCursor == "" || last.NextCursor != "" {
+			t.Fatalf("last page cursors = %+v, want only a previous cursor", last)
+		}
+
+		// the previous cursors lead back to the first page
+		back := page(last.PrevCursor, []string{"Heat", "The Godfather"})
+		page(back.PrevCursor, []string{"Alien", "Blade Runner"})
+
+		// movies created meanwhile do not shift the next page
+		app.do(t, fiber.MethodPost, "/api/movies", newMovieBody("Aliens", "1986-07-18", 8.4, 137, "James Cameron", "science-fiction")).
+			expect(t, fiber.StatusCreated)
+		page(first.NextCursor, []string{"Heat", "The Godfather"})
+
+		app.do(t, fiber.MethodGet, "/api/movies?cursor=forged", nil).expect(t, fiber.StatusBadRequest)
+	})
+}
+
+func TestCreateMovie(t *testing.T) {
+	runBackends(t, func(t *testing.T, app *testApp) {
+		body := newMovieBody("Heat", "1995-12-15", 8.3, 170, "Michael Mann", "crime", "drama")
+		res := app.do(t, fiber.MethodPost, "/api/movies", body).expect(t, fiber.StatusCreated)
+
+		movie := decode[models.Movie](t, res.body.Data)
+		if movie.ID == 0 || movie.Version != 1 || movie.Title != "Heat" || len(movie.Genres) != 2 || movie.Genres[0].Name != "Crime" {
+			t.Errorf("created movie = %+v, want Heat at version 1 with its genres", movie)
+		}
+		if etag := res.header.Get(fiber.HeaderETag); etag != movie.ETag() {
+			t.Errorf("ETag = %s, want %s", etag, movie.ETag())
+		}
+
+		// the created movie is served, and not served again to a client holding it
+		res = app.do(t, fiber.MethodGet, "/api/movies/"+strconv.Itoa(int(movie.ID)), nil).expect(t, fiber.StatusOK)
+		if got := decode[models.Movie](t, res.body.Data); got.Title != "Heat" || got.ReleaseDate[:10] != "1995-12-15" || got.Rating != 8.3 {
+			t.Errorf("fetched movie = %+v, want Heat", got)
+		}
+		app.do(t, fiber.MethodGet, "/api/movies/"+strconv.Itoa(int(movie.ID)), nil, fiber.HeaderIfNoneMatch, movie.ETag()).
+			expect(t, fiber.StatusNotModified)
+		app.do(t, fiber.MethodGet, "/api/movies/999", nil).expect(t, fiber.StatusNotFound)
+
+		// invalid movies and unknown genres are rejected
+		invalid := newMovieBody("", "1995-12-15", 8.3, 170, "Michael Mann", "crime")
+		app.do(t, fiber.MethodPost, "/api/movies", invalid).expect(t, fiber.StatusBadRequest)
+		unknown := newMovieBody("Thief", "1981-03-27", 7.4, 123, "Michael Mann", "neo-noir")
+		res = app.do(t, fiber.MethodPost, "/api/movies", unknown).expect(t, fiber.StatusBadRequest)
+		if errs := decode[[]string](t, res.body.Error); len(errs) != 1 || errs[0] != `Genres: Unknown genre "neo-noir"` {
+			t.Errorf("errors = %v, want the unknown genre", errs)
+		}
+	})
+}
+
+func TestUpdateMovie(t *testing.T) {
+	runBackends(t, func(t *testing.T, app *testApp) {
+		movie := createMovies(t, app)["Alien"]
+		path := "/api/movies/" + strconv.Itoa(int(movie.ID))
+		body := newMovieBody("Alien", "1979-05-25", 8.6, 117, "Ridley Scott", "science-fiction", "drama")
+
+		// a stale version is refused without changes
+		app.do(t, fiber.MethodPut, path, body, fiber.HeaderIfMatch, `"`+strconv.Itoa(int(movie.ID))+`-7"`).
+			expect(t, fiber.StatusPreconditionFailed)
+
+		// the current version is updated to the next one
+		res := app.do(t, fiber.MethodPut, path, body, fiber.HeaderIfMatch, movie.ETag()).expect(t, fiber.StatusOK)
+		updated := decode[models.Movie](t, res.body.Data)
+		if updated.Version != 2 || updated.Rating != 8.6 || len(updated.Genres) != 2 {
+			t.Errorf("updated movie = %+v, want version 2 rated 8.6 with two genres", updated)
+		}
+		if etag := res.header.Get(fiber.HeaderETag); etag != updated.ETag() {
+			t.Errorf("ETag = %s, want %s", etag, updated.ETag())
+		}
+
+		// the replaced version is stale now, updates without If-Match still apply
+		app.do(t, fiber.MethodPut, path, body, fiber.HeaderIfMatch, movie.ETag()).expect(t, fiber.StatusPreconditionFailed)
+		res = app.do(t, fiber.MethodPut, path, newMovieBody("Alien", "1979-05-25", 8.5, 117, "Ridley Scott", "science-fiction")).
+			expect(t, fiber.StatusOK)
+		if got := decode[models.Movie](t, res.body.Data); got.Version != 3 || got.Rating != 8.5 {
+			t.Errorf("updated movie = %+v, want version 3 rated 8.5", got)
+		}
+
+		app.do(t, fiber.MethodPut, "/api/movies/999", body).expect(t, fiber.StatusNotFound)
+		app.do(t, fiber.MethodPut, path, newMovieBody("Alien", "25/05/1979", 8.5, 117, "Ridley Scott", "science-fiction")).
+			expect(t, fiber.StatusBadRequest)
+	})
+}
+
+func TestPatchMovie(t *testing.T) {
+	runBackends(t, func(t *testing.T, app *testApp) {
+		movie := createMovies(t, app)["Alien"]
+		path := "/api/movies/" + strconv.Itoa(int(movie.ID))
+		mergePatch := []string{fiber.HeaderContentType, mergePatchMediaType}
+		jsonPatch := []string{fiber.HeaderContentType, jsonPatchMediaType}
+
+		// a stale version is refused without changes
+		app.do(t, fiber.MethodPatch, path, map[string]interface{}{"rating": 8.6},
+			append(mergePatch, fiber.HeaderIfMatch, `"`+strconv.Itoa(int(movie.ID))+`-7"`)...).
+			expect(t, fiber.StatusPreconditionFailed)
+
+		// a merge patch updates only the fields it names
+		res := app.do(t, fiber.MethodPatch, path, map[string]interface{}{"rating": 8.6, "genres": []string{"science-fiction", "drama"}},
+			append(mergePatch, fiber.HeaderIfMatch, movie.ETag())...).expect(t, fiber.StatusOK)
+		patched := decode[models.Movie](t, res.body.Data)
+		if patched.Version != 2 || patched.Rating != 8.6 || len(patched.Genres) != 2 || patched.Title != "Alien" || patched.DurationMinutes != 117 {
+			t.Errorf("patched movie = %+v, want version 2 rated 8.6 with two genres", patched)
+		}
+		if etag := res.header.Get(fiber.HeaderETag); etag != patched.ETag() {
+			t.Errorf("ETag = %s, want %s", etag, patched.ETag())
+		}
+		app.do(t, fiber.MethodPatch, path, map[string]interface{}{"rating": 8.7}, append(mergePatch, fiber.HeaderIfMatch, movie.ETag())...).
+			expect(t, fiber.StatusPreconditionFailed)
+
+		// null removes a field, which required fields refuse
+		app.do(t, fiber.MethodPatch, path, map[string]interface{}{"description": nil}, mergePatch...).expect(t, fiber.StatusBadRequest)
+		app.do(t, fiber.MethodPatch, path, map[string]interface{}{"genres": nil}, mergePatch...).expect(t, fiber.StatusBadRequest)
+
+		// JSON Patch operations apply only when their tests pass
+		operations := func(title string) []map[string]interface{} {
+			return []map[string]interface{}{
+				{"op": "test", "path": "/title", "value": title},
+				{"op": "replace", "path": "/duration_minutes", "value": 116},
+			}
+		}
+		res = app.do(t, fiber.MethodPatch, path, operations("Aliens"), jsonPatch...).expect(t, fiber.StatusBadRequest)
+		if res.body.Message != "Invalid patch" {
+			t.Errorf("failed test message = %q, want Invalid patch", res.body.Message)
+		}
+		res = app.do(t, fiber.MethodPatch, path, operations("Alien"), jsonPatch...).expect(t, fiber.StatusOK)
+		if got := decode[models.Movie](t, res.body.Data); got.Version != 3 || got.DurationMinutes != 116 || got.Rating != 8.6 {
+			t.Errorf("patched movie = %+v, want version 3 lasting 116 minutes", got)
+		}
+
+		// fields outside the movie document cannot be patched
+		app.do(t, fiber.MethodPatch, path, map[string]interface{}{"id": 7}, mergePatch...).expect(t, fiber.StatusBadRequest)
+		app.do(t, fiber.MethodPatch, path, []map[string]interface{}{{"op": "replace", "path": "/version", "value": 9}}, jsonPatch...).
+			expect(t, fiber.StatusBadRequest)
+		app.do(t, fiber.MethodPatch, path, []map[string]interface{}{{"op": "add", "path": "/budget", "value": 11000000}}, jsonPatch...).
+			expect(t, fiber.StatusBadRequest)
+
+		res = app.do(t, fiber.MethodGet, path, nil).expect(t, fiber.StatusOK)
+		if got := decode[models.Movie](t, res.body.Data); got.Version != 3 || got.Description == "" {
+			t.Errorf("movie = %+v, want version 3 untouched by the refused patches", got)
+		}
+		app.do(t, fiber.MethodPatch, path, map[string]interface{}{"rating": 8.6}, fiber.HeaderContentType, fiber.MIMETextPlain).
+			expect(t, fiber.StatusUnsupportedMediaType)
+		app.do(t, fiber.MethodPatch, "/api/movies/999", map[string]interface{}{"rating": 8.6}, mergePatch...).expect(t, fiber.StatusNotFound)
+	})
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+const (
+	mergePatchMediaType = "application/merge-patch+json"
+	jsonPatchMediaType  = "application/json-patch+json"
+)
+
+var errUnsupportedPatch = errors.New("supported media types are " + mergePatchMediaType + " and " + jsonPatchMediaType)
+
+// PatchMovie godoc
+// @Summary      Patch a movie
+// @Description  partially update a movie with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902), only the patched fields are validated and updated
+// @Tags         movies
+// @Accept       json
+// @Accept       application/merge-patch+json
+// @Accept       application/json-patch+json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id        path      string  true   "Movie ID"
+// @Param        If-Match  header    string  false  "ETag of the movie being patched"
+// @Param        patch     body      object  true   "Merge patch document or JSON Patch operations"
+// @Success      200  {object}  utils.SuccessResponse{data=models.Movie} "Movie patched successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid patch or validation failed"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      404  {object}  utils.ErrorResponse "Movie not found"
+// @Failure      412  {object}  utils.ErrorResponse "Movie has been modified"
+// @Failure      415  {object}  utils.ErrorResponse "Unsupported patch media type"
+// @Failure      428  {object}  utils.ErrorResponse "If-Match header is required"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to patch movie"
+// @Router       /api/movies/{id} [patch]
+func (h *Handler) PatchMovie(ctx *fiber.Ctx) error {
+	// fetch the existing movie and its genres
+	movie, err := h.Movies.Get(ctx.UserContext(), paramID(ctx, "id"))
+	if err != nil {
+		return utils.NotFoundResponse(ctx, "Movie not found", err.Error())
+	}
+
+	// make sure the client patches the version it has seen
+	if !ifMatch(ctx, movie) {
+		return utils.PreconditionFailedResponse(ctx, "Movie has been modified", errMovieModified.Error())
+	}
+
+	// build the patchable document of the current movie
+	document, err := sonic.Marshal(movieDocument(movie))
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to patch movie", err.Error())
+	}
+
+	// apply the patch according to its media type
+	patched, fields, err := applyPatch(ctx.Get(fiber.HeaderContentType), ctx.Body(), document)
+	if errors.Is(err, errUnsupportedPatch) {
+		return utils.UnsupportedMediaTypeResponse(ctx, "Unsupported patch media type", err.Error())
+	}
+	if err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid patch", err.Error())
+	}
+
+	// map the patched fields to struct fields, rejecting read-only fields
+	var structFields, invalid []string
+	for _, field := range fields {
+		structField, ok := models.MoviePatchFields[field]
+		if !ok {
+			invalid = append(invalid, field+": This field cannot be patched")
+			continue
+		}
+		if !slices.Contains(structFields, structField) {
+			structFields = append(structFields, structField)
+		}
+	}
+	if invalid != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", invalid)
+	}
+
+	// decode the patched document
+	req := new(models.Movie)
+	if err := sonic.Unmarshal(patched, req); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid patch", err.Error())
+	}
+
+	// validate only the patched fields
+	if err := validators.ValidateStructPartial(req, structFields...); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// resolve the referenced genres when they are patched
+	patchGenres := slices.Contains(structFields, "Genres")
+	var genres []models.Genre
+	if patchGenres {
+		var invalid []string
+		if genres, invalid, err = h.resolveGenres(ctx.UserContext(), req.Genres); err != nil {
+			return utils.InternalServerErrorResponse(ctx, "Failed to resolve genres", err.Error())
+		}
+		if invalid != nil {
+			return utils.BadRequestResponse(ctx, "Validation failed", invalid)
+		}
+	}
+
+	// apply only the patched fields
+	previous := movieDocument(movie)
+	target, patch := reflect.ValueOf(movie).Elem(), reflect.ValueOf(req).Elem()
+	for _, field := range structFields {
+		if field != "Genres" {
+			target.FieldByName(field).Set(patch.FieldByName(field))
+		}
+	}
+	if patchGenres {
+		movie.Genres = genres
+	}
+
+	// update the movie with its genres and director credit, and its history
+	err = h.Tx.WithinTransaction(ctx.UserContext(), func(tx context.Context) error {
+		if err := h.Movies.Update(tx, movie); err != nil {
+			return err
+		}
+		return h.recordRevision(ctx, tx, models.RevisionUpdate, movie, previous)
+	})
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		return utils.PreconditionFailedResponse(ctx, "Movie has been modified", errMovieModified.Error())
+	}
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to patch movie", err.Error())
+	}
+
+	// return success response
+	ctx.Set(fiber.HeaderETag, movie.ETag())
+	return utils.OKResponse(ctx, "Movie patched successfully", movie)
+}
+
+// movieDocument returns the patchable representation of a movie, with
+// genres referenced by slug. Revision snapshots use the same representation.
+func movieDocument(movie *models.Movie) map[string]interface{} {
+	genres := make([]string, len(movie.Genres))
+	for i, genre := range movie.Genres {
+		genres[i] = genre.Slug
+	}
+
+	releaseDate := movie.ReleaseDate
+	if len(releaseDate) > len("2006-01-02") {
+		releaseDate = releaseDate[:len("2006-01-02")]
+	}
+
+	return map[string]interface{}{
+		"title":            movie.Title,
+		"description":      movie.Description,
+		"poster_url":       movie.PosterURL,
+		"release_date":     releaseDate,
+		"rating":           movie.Rating,
+		"duration_minutes": movie.DurationMinutes,
+		"director":         movie.Director,
+		"genres":           genres,
+	}
+}
+
+// applyPatch applies a merge patch or a JSON Patch to the document and
+// returns the patched document with the top-level fields the patch modifies.
+func applyPatch(contentType string, patch, document []byte) ([]byte, []string, error) {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+
+	switch mediaType {
+	case jsonPatchMediaType:
+		operations, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var fields []string
+		for _, operation := range operations {
+			if operation.Kind() == "test" {
+				continue
+			}
+			paths := []func() (string, error){operation.Path}
+			if operation.Kind() == "move" {
+				paths = append(paths, operation.From)
+			}
+			for _, path := range paths {
+				pointer, err := path()
+				if err != nil {
+					return nil, nil, err
+				}
+				fields = append(fields, patchField(pointer))
+			}
+		}
+
+		patched, err := operations.Apply(document)
+		return patched, fields, err
+
+	case mergePatchMediaType, fiber.MIMEApplicationJSON:
+		var members map[string]interface{}
+		if err := sonic.Unmarshal(patch, &members); err != nil {
+			return nil, nil, errors.New("merge patch must be a JSON object")
+		}
+
+		fields := make([]string, 0, len(members))
+		for field := range members {
+			fields = append(fields, field)
+		}
+
+		patched, err := jsonpatch.MergePatch(document, patch)
+		return patched, fields, err
+
+	default:
+		return nil, nil, errUnsupportedPatch
+	}
+}
+
+// patchField returns the top-level member targeted by a JSON pointer.
+func patchField(pointer string) string {
+	field, _, _ := strings.Cut(strings.TrimPrefix(pointer, "/"), "/")
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(field)
+}
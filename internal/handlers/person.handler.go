@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+// ListPeople godoc
+// @Summary      List people
+// @Description  get a paginated list of people, optionally filtered by name
+// @Tags         people
+// @Accept       json
+// @Produce      json
+// @Param        q         query     string  false  "Name (partial match)"
+// @Param        page      query     int     false  "Page number"  minimum(1)
+// @Param        per_page  query     int     false  "People per page"  minimum(1)  maximum(100)
+// @Success      200  {object}  utils.SuccessResponse{data=[]models.Person,meta=utils.Pagination} "People fetched successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid query parameters"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to fetch people"
+// @Router       /api/people [get]
+func (h *Handler) ListPeople(ctx *fiber.Ctx) error {
+	// parse the query parameters
+	query := new(queries.PersonListQuery)
+	if err := ctx.QueryParser(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid query parameters", err.Error())
+	}
+
+	// validate the query parameters
+	if err := validators.ValidateStruct(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// fetch the requested page of people and count the people matching the filter
+	people, total, err := h.People.List(ctx.UserContext(), query)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch people", err.Error())
+	}
+
+	// return success response with people data and pagination metadata
+	pagination := utils.NewPagination(ctx, query.CurrentPage(), query.Size(), total)
+	return utils.PaginatedResponse(ctx, "People fetched successfully", people, pagination)
+}
+
+// GetPerson godoc
+// @Summary      Get a person
+// @Description  get person by ID
+// @Tags         people
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Person ID"
+// @Success      200  {object}  utils.SuccessResponse{data=models.Person} "Person fetched successfully"
+// @Failure      404  {object}  utils.ErrorResponse "Person not found"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to fetch person"
+// @Router       /api/people/{id} [get]
+func (h *Handler) GetPerson(ctx *fiber.Ctx) error {
+	// fetch the person by ID
+	person, err := h.People.Get(ctx.UserContext(), paramID(ctx, "id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Person not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch person", err.Error())
+	}
+
+	// return success response with person data
+	return utils.OKResponse(ctx, "Person fetched successfully", person)
+}
+
+// CreatePerson godoc
+// @Summary      Create a person
+// @Description  create a new person
+// @Tags         people
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        person  body      models.Person  true  "Person data"
+// @Success      201  {object}  utils.SuccessResponse{data=models.Person} "Person created successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid request body or validation failed"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to create person"
+// @Router       /api/people [post]
+func (h *Handler) CreatePerson(ctx *fiber.Ctx) error {
+	// initialize a new person instance
+	person := new(models.Person)
+
+	// parse the request body
+	if err := ctx.BodyParser(person); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid request body", err.Error())
+	}
+
+	// validate the person struct
+	if err := validators.ValidateStruct(person); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// create the person record
+	if err := h.People.Create(ctx.UserContext(), person); err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to create person", err.Error())
+	}
+
+	// return success response
+	return utils.CreatedResponse(ctx, "Person created successfully", person)
+}
+
+// UpdatePerson godoc
+// @Summary      Update a person
+// @Description  update an existing person by ID
+// @Tags         people
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id      path      string         true  "Person ID"
+// @Param        person  body      models.Person  true  "Updated person data"
+// @Success      200  {object}  utils.SuccessResponse{data=models.Person} "Person updated successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid request body or validation failed"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      404  {object}  utils.ErrorResponse "Person not found"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to update person"
+// @Router       /api/people/{id} [put]
+func (h *Handler) UpdatePerson(ctx *fiber.Ctx) error {
+	// fetch the existing person
+	person, err := h.People.Get(ctx.UserContext(), paramID(ctx, "id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Person not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to update person", err.Error())
+	}
+
+	// initialize a new person instance to hold the updated data and parse the request body
+	req := new(models.Person)
+	if err := ctx.BodyParser(req); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid request body", err.Error())
+	}
+
+	// validate the updated person data
+	if err := validators.ValidateStruct(req); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// update the person record
+	person.Name = req.Name
+	person.Biography = req.Biography
+	person.BirthDate = req.BirthDate
+	person.PhotoURL = req.PhotoURL
+	if err := h.People.Update(ctx.UserContext(), person); err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to update person", err.Error())
+	}
+
+	// return success response
+	return utils.OKResponse(ctx, "Person updated successfully", person)
+}
+
+// DeletePerson godoc
+// @Summary      Delete a person
+// @Description  delete a person by ID, people with credits cannot be deleted
+// @Tags         people
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Person ID"
+// @Success      200  {object}  utils.SuccessResponse "Person deleted successfully"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      404  {object}  utils.ErrorResponse "Person not found"
+// @Failure      409  {object}  utils.ErrorResponse "Person still has credits"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to delete person"
+// @Router       /api/people/{id} [delete]
+func (h *Handler) DeletePerson(ctx *fiber.Ctx) error {
+	// fetch the existing person
+	person, err := h.People.Get(ctx.UserContext(), paramID(ctx, "id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Person not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to delete person", err.Error())
+	}
+
+	// refuse to delete people that are still credited
+	credits, err := h.Credits.CountByPerson(ctx.UserContext(), person.ID)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to delete person", err.Error())
+	}
+	if credits > 0 {
+		return utils.ConflictResponse(ctx, "Person still has credits", fmt.Sprintf("%d credits reference this person", credits))
+	}
+
+	// delete the person record
+	if err := h.People.Delete(ctx.UserContext(), person); err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to delete person", err.Error())
+	}
+
+	// return success response
+	return utils.OKResponse(ctx, "Person deleted successfully", nil)
+}
+
+// GetFilmography godoc
+// @Summary      Get a filmography
+// @Description  get every credit of a person with the credited movies, newest first
+// @Tags         people
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Person ID"
+// @Success      200  {object}  utils.SuccessResponse{data=models.Filmography} "Filmography fetched successfully"
+// @Failure      404  {object}  utils.ErrorResponse "Person not found"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to fetch filmography"
+// @Router       /api/people/{id}/filmography [get]
+func (h *Handler) GetFilmography(ctx *fiber.Ctx) error {
+	// fetch the person by ID
+	person, err := h.People.Get(ctx.UserContext(), paramID(ctx, "id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Person not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch filmography", err.Error())
+	}
+
+	// fetch the credits of the person with their movies, leaving out trashed movies
+	credits, err := h.Credits.ListByPerson(ctx.UserContext(), person.ID)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch filmography", err.Error())
+	}
+
+	// return success response with filmography data
+	filmography := models.Filmography{Person: *person, Credits: credits}
+	return utils.OKResponse(ctx, "Filmography fetched successfully", filmography)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+// SearchMovies godoc
+// @Summary      Search movies
+// @Description  full-text search across title, director and description, ranked by relevance, outside PostgreSQL the words and quoted phrases match as substrings
+// @Tags         movies
+// @Accept       json
+// @Produce      json
+// @Param        q         query     string  true   "Search query, supports quoted phrases, OR and -exclusions"
+// @Param        page      query     int     false  "Page number"  minimum(1)
+// @Param        per_page  query     int     false  "Movies per page"  minimum(1)  maximum(100)
+// @Param        limit     query     int     false  "Alternative to per_page"  minimum(1)  maximum(100)
+// @Param        offset    query     int     false  "Alternative to page"  minimum(0)
+// @Success      200  {object}  utils.SuccessResponse{data=[]models.MovieSearchResult,meta=utils.Pagination} "Movies found successfully"
+// @Failure      204  {object}  utils.ErrorResponse "No movies match the search query"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid query parameters"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to search movies"
+// @Router       /api/movies/search [get]
+func (h *Handler) SearchMovies(ctx *fiber.Ctx) error {
+	// parse the query parameters
+	query := new(queries.MovieSearchQuery)
+	if err := ctx.QueryParser(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid query parameters", err.Error())
+	}
+
+	// validate the query parameters
+	if err := validators.ValidateStruct(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// fetch the requested page of results ordered by relevance and count the matching movies
+	results, total, err := h.Movies.Search(ctx.UserContext(), query)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to search movies", err.Error())
+	}
+
+	// check if results slice is empty
+	if len(results) == 0 {
+		return utils.NoContentResponse(ctx, "No movies match the search query")
+	}
+
+	// return success response with results and pagination metadata
+	pagination := utils.NewPagination(ctx, query.CurrentPage(), query.Size(), total)
+	return utils.PaginatedResponse(ctx, "Movies found successfully", results, pagination)
+}
+
+// AutocompleteMovies godoc
+// @Summary      Autocomplete movie titles
+// @Description  typo-tolerant title suggestions using trigram word similarity on title and director, computed by the API outside PostgreSQL
+// @Tags         movies
+// @Accept       json
+// @Produce      json
+// @Param        q      query     string  true   "Partial or misspelled title or director"
+// @Param        limit  query     int     false  "Maximum number of suggestions"  minimum(1)  maximum(20)
+// @Success      200  {object}  utils.SuccessResponse{data=[]models.MovieSuggestion} "Suggestions fetched successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid query parameters"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to fetch suggestions"
+// @Router       /api/movies/autocomplete [get]
+func (h *Handler) AutocompleteMovies(ctx *fiber.Ctx) error {
+	// parse the query parameters
+	query := new(queries.MovieAutocompleteQuery)
+	if err := ctx.QueryParser(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid query parameters", err.Error())
+	}
+
+	// validate the query parameters
+	if err := validators.ValidateStruct(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// fetch the most similar titles
+	suggestions, err := h.Movies.Autocomplete(ctx.UserContext(), query)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch suggestions", err.Error())
+	}
+
+	// return success response with suggestions
+	return utils.OKResponse(ctx, "Suggestions fetched successfully", suggestions)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+func TestSearchMovies(t *testing.T) {
+	runBackends(t, func(t *testing.T, app *testApp) {
+		createMovies(t, app)
+
+		tests := []struct {
+			q         string
+			want      []string
+			highlight string
+		}{
+			{"godfather -part", []string{"The Godfather"}, "The <mark>Godfather</mark>"},
+			{"runner", []string{"Blade Runner"}, "Blade <mark>Runner</mark>"},
+			{"alien scott", []string{"Alien"}, "<mark>Alien</mark>"},
+			{"scott", []string{"Alien", "Blade Runner"}, ""},
+			{"mann OR coppola", []string{"Heat", "The Godfather", "The Godfather Part II"}, ""},
+			{"scott or mann -alien", []string{"Alien", "Blade Runner", "Heat"}, ""},
+			{"coppola part or alien", []string{"Alien", "The Godfather Part II"}, ""},
+			{`"blade runner"`, []string{"Blade Runner"}, ""},
+			{`godfather -"part ii"`, []string{"The Godfather"}, ""},
+		}
+		for _, tt := range tests {
+			res := app.do(t, fiber.MethodGet, "/api/movies/search?q="+url.QueryEscape(tt.q), nil).expect(t, fiber.StatusOK)
+			results := decode[[]models.MovieSearchResult](t, res.body.Data)
+
+			// equally ranked results may come in any order of relevance
+			got := make([]string, len(results))
+			for i, result := range results {
+				got[i] = result.Title
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("search %q = %v, want %v", tt.q, got, tt.want)
+				continue
+			}
+			if pagination := decode[utils.Pagination](t, res.body.Meta); pagination.Total != int64(len(tt.want)) {
+				t.Errorf("search %q total = %d, want %d", tt.q, pagination.Total, len(tt.want))
+			}
+			if tt.highlight != "" && results[0].TitleHighlight != tt.highlight {
+				t.Errorf("search %q title highlight = %q, want %q", tt.q, results[0].TitleHighlight, tt.highlight)
+			}
+			if len(results[0].Genres) == 0 || results[0].Rank <= 0 {
+				t.Errorf("search %q result = %+v, want it ranked with its genres", tt.q, results[0])
+			}
+		}
+
+		// pages count every match, no match is an empty answer
+		res := app.do(t, fiber.MethodGet, "/api/movies/search?q=scott&per_page=1&page=2", nil).expect(t, fiber.StatusOK)
+		if results := decode[[]models.MovieSearchResult](t, res.body.Data); len(results) != 1 {
+			t.Errorf("page 2 = %d results, want 1", len(results))
+		}
+		res = app.do(t, fiber.MethodGet, "/api/movies/search?q=zombies", nil).expect(t, fiber.StatusOK)
+		if res.body.Message != "No movies match the search query" {
+			t.Errorf("message = %q, want no match", res.body.Message)
+		}
+		app.do(t, fiber.MethodGet, "/api/movies/search?q=a", nil).expect(t, fiber.StatusBadRequest)
+	})
+}
+
+func TestAutocompleteMovies(t *testing.T) {
+	runBackends(t, func(t *testing.T, app *testApp) {
+		createMovies(t, app)
+
+		// misspelled inputs match by trigrams, the most similar titles first
+		tests := []struct {
+			query string
+			want  []string
+		}{
+			{"q=godfater", []string{"The Godfather", "The Godfather Part II"}},
+			{"q=godfater&limit=1", []string{"The Godfather"}},
+			{"q=god", []string{"The Godfather", "The Godfather Part II"}},
+			{"q=blade+runer", []string{"Blade Runner"}},
+			{"q=scot", []string{"Alien", "Blade Runner"}},
+			{"q=al", []string{"Alien"}},
+			{"q=zombies", []string{}},
+		}
+		for _, tt := range tests {
+			res := app.do(t, fiber.MethodGet, "/api/movies/autocomplete?"+tt.query, nil).expect(t, fiber.StatusOK)
+			suggestions := decode[[]models.MovieSuggestion](t, res.body.Data)
+			got := make([]string, len(suggestions))
+			for i, suggestion := range suggestions {
+				got[i] = suggestion.Title
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("autocomplete %s = %v, want %v", tt.query, got, tt.want)
+			}
+		}
+
+		res := app.do(t, fiber.MethodGet, "/api/movies/autocomplete?q=alien", nil).expect(t, fiber.StatusOK)
+		if suggestions := decode[[]models.MovieSuggestion](t, res.body.Data); len(suggestions) != 1 || suggestions[0].ReleaseYear != 1979 {
+			t.Errorf("suggestions = %+v, want Alien of 1979", suggestions)
+		}
+		app.do(t, fiber.MethodGet, "/api/movies/autocomplete", nil).expect(t, fiber.StatusBadRequest)
+	})
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+// ListTrashedMovies godoc
+// @Summary      List trashed movies
+// @Description  get a paginated list of deleted movies, most recently deleted first
+// @Tags         movies
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        page      query     int     false  "Page number"  minimum(1)
+// @Param        per_page  query     int     false  "Movies per page"  minimum(1)  maximum(100)
+// @Success      200  {object}  utils.SuccessResponse{data=[]models.Movie,meta=utils.Pagination} "Trashed movies fetched successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid query parameters"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to fetch trashed movies"
+// @Router       /api/movies/trash [get]
+func (h *Handler) ListTrashedMovies(ctx *fiber.Ctx) error {
+	// parse the query parameters
+	query := new(queries.PageQuery)
+	if err := ctx.QueryParser(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid query parameters", err.Error())
+	}
+
+	// validate the query parameters
+	if err := validators.ValidateStruct(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// fetch the requested page of trashed movies and count them
+	movies, total, err := h.Movies.ListTrashed(ctx.UserContext(), query)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch trashed movies", err.Error())
+	}
+
+	// return success response with movies data and pagination metadata
+	pagination := utils.NewPagination(ctx, query.CurrentPage(), query.Size(), total)
+	return utils.PaginatedResponse(ctx, "Trashed movies fetched successfully", movies, pagination)
+}
+
+// RestoreMovie godoc
+// @Summary      Restore a movie
+// @Description  bring a deleted movie back from the trash by ID
+// @Tags         movies
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id        path      string  true   "Movie ID"
+// @Param        If-Match  header    string  false  "ETag of the movie being restored"
+// @Success      200  {object}  utils.SuccessResponse{data=models.Movie} "Movie restored successfully"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      404  {object}  utils.ErrorResponse "Movie not found in trash"
+// @Failure      412  {object}  utils.ErrorResponse "Movie has been modified"
+// @Failure      428  {object}  utils.ErrorResponse "If-Match header is required"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to restore movie"
+// @Router       /api/movies/{id}/restore [post]
+func (h *Handler) RestoreMovie(ctx *fiber.Ctx) error {
+	// fetch the trashed movie and its genres
+	movie, err := h.Movies.Get(ctx.UserContext(), paramID(ctx, "id"), repositories.OnlyTrashed())
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "Movie not found in trash", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to restore movie", err.Error())
+	}
+
+	// make sure the client restores the version it has seen
+	if !ifMatch(ctx, movie) {
+		return utils.PreconditionFailedResponse(ctx, "Movie has been modified", errMovieModified.Error())
+	}
+
+	// take the movie out of the trash, its genre links and credits were kept
+	err = h.Tx.WithinTransaction(ctx.UserContext(), func(tx context.Context) error {
+		if err := h.Movies.Restore(tx, movie); err != nil {
+			return err
+		}
+		return h.recordRevision(ctx, tx, models.RevisionRestore, movie, nil)
+	})
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		return utils.PreconditionFailedResponse(ctx, "Movie has been modified", errMovieModified.Error())
+	}
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to restore movie", err.Error())
+	}
+
+	// return success response with movie data
+	ctx.Set(fiber.HeaderETag, movie.ETag())
+	return utils.OKResponse(ctx, "Movie Restored successfully", movie)
+}
+
+const (
+	// trashPurgeBatchSize bounds how many expired movies are loaded at once.
+	trashPurgeBatchSize = 100
+	// trashPurgeActor names the retention purge in the audit history.
+	trashPurgeActor = "system:trash-purge"
+)
+
+// PurgeTrash permanently deletes the movies moved to the trash before the
+// cutoff and returns how many it deleted. Every movie is deleted at the
+// version it was loaded, so a movie restored meanwhile is kept, and records
+// its purge in the history.
+func (h *Handler) PurgeTrash(ctx context.Context, cutoff time.Time) (int, error) {
+	purged := 0
+	for {
+		movies, err := h.Movies.ListExpired(ctx, cutoff, trashPurgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		for i := range movies {
+			movie := &movies[i]
+			err := h.Tx.WithinTransaction(ctx, func(tx context.Context) error {
+				if err := h.Movies.Delete(tx, movie, repositories.Permanently()); err != nil {
+					return err
+				}
+				return h.writeRevision(tx, trashPurgeActor, "", models.RevisionPurge, movie, movieDocument(movie))
+			})
+			if errors.Is(err, repositories.ErrVersionConflict) {
+				continue
+			}
+			if err != nil {
+				return purged, err
+			}
+			purged++
+		}
+
+		// movies changed meanwhile left the trash or got a later deletion time
+		if len(movies) < trashPurgeBatchSize {
+			return purged, nil
+		}
+	}
+}
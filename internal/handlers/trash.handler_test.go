@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+func TestDeleteAndRestoreMovie(t *testing.T) {
+	runBackends(t, func(t *testing.T, app *testApp) {
+		movie := createMovies(t, app)["Heat"]
+		path := "/api/movies/" + strconv.Itoa(int(movie.ID))
+
+		// a stale version is not deleted
+		app.do(t, fiber.MethodDelete, path, nil, fiber.HeaderIfMatch, `"`+strconv.Itoa(int(movie.ID))+`-2"`).
+			expect(t, fiber.StatusPreconditionFailed)
+		app.do(t, fiber.MethodGet, path, nil).expect(t, fiber.StatusOK)
+
+		// the current version moves to the trash and out of the listings
+		app.do(t, fiber.MethodDelete, path, nil, fiber.HeaderIfMatch, movie.ETag()).expect(t, fiber.StatusOK)
+		app.do(t, fiber.MethodGet, path, nil).expect(t, fiber.StatusNotFound)
+		app.do(t, fiber.MethodDelete, path, nil).expect(t, fiber.StatusNotFound)
+		res := app.do(t, fiber.MethodGet, "/api/movies?sort=title", nil).expect(t, fiber.StatusOK)
+		if got := titles(t, res); slices.Contains(got, "Heat") || len(got) != 4 {
+			t.Errorf("movies = %v, want the four movies outside the trash", got)
+		}
+
+		res = app.do(t, fiber.MethodGet, "/api/movies/trash", nil).expect(t, fiber.StatusOK)
+		trashed := decode[[]models.Movie](t, res.body.Data)
+		if len(trashed) != 1 || trashed[0].ID != movie.ID || trashed[0].Version != 2 || !trashed[0].DeletedAt.Valid {
+			t.Fatalf("trash = %+v, want Heat deleted at version 2", trashed)
+		}
+		if pagination := decode[utils.Pagination](t, res.body.Meta); pagination.Total != 1 {
+			t.Errorf("trash total = %d, want 1", pagination.Total)
+		}
+
+		// restoring needs the trashed version and brings the movie back
+		app.do(t, fiber.MethodPost, path+"/restore", nil, fiber.HeaderIfMatch, movie.ETag()).
+			expect(t, fiber.StatusPreconditionFailed)
+		res = app.do(t, fiber.MethodPost, path+"/restore", nil, fiber.HeaderIfMatch, trashed[0].ETag()).expect(t, fiber.StatusOK)
+		restored := decode[models.Movie](t, res.body.Data)
+		if restored.Version != 3 || restored.DeletedAt.Valid || len(restored.Genres) != 1 {
+			t.Errorf("restored movie = %+v, want version 3 with its genre", restored)
+		}
+		app.do(t, fiber.MethodGet, path, nil).expect(t, fiber.StatusOK)
+		app.do(t, fiber.MethodPost, path+"/restore", nil).expect(t, fiber.StatusNotFound)
+		res = app.do(t, fiber.MethodGet, "/api/movies/trash", nil).expect(t, fiber.StatusOK)
+		if trashed := decode[[]models.Movie](t, res.body.Data); len(trashed) != 0 {
+			t.Errorf("trash = %+v, want it empty", trashed)
+		}
+	})
+}
+
+func TestPurgeTrash(t *testing.T) {
+	runBackends(t, func(t *testing.T, app *testApp) {
+		movies := createMovies(t, app)
+		for _, title := range []string{"Heat", "Alien"} {
+			movie := movies[title]
+			app.do(t, fiber.MethodDelete, "/api/movies/"+strconv.Itoa(int(movie.ID)), nil, fiber.HeaderIfMatch, movie.ETag()).
+				expect(t, fiber.StatusOK)
+		}
+
+		// movies trashed after the cutoff are kept
+		purged, err := app.h.PurgeTrash(context.Background(), time.Now().Add(-time.Hour))
+		if err != nil || purged != 0 {
+			t.Fatalf("purge before the deletes = %d, %v, want none", purged, err)
+		}
+
+		// a restored movie is out of reach of the purge
+		alien := "/api/movies/" + strconv.Itoa(int(movies["Alien"].ID))
+		res := app.do(t, fiber.MethodGet, "/api/movies/trash", nil).expect(t, fiber.StatusOK)
+		for _, trashed := range decode[[]models.Movie](t, res.body.Data) {
+			if trashed.ID == movies["Alien"].ID {
+				app.do(t, fiber.MethodPost, alien+"/restore", nil, fiber.HeaderIfMatch, trashed.ETag()).expect(t, fiber.StatusOK)
+			}
+		}
+
+		purged, err = app.h.PurgeTrash(context.Background(), time.Now().Add(time.Minute))
+		if err != nil || purged != 1 {
+			t.Fatalf("purge = %d, %v, want Heat only", purged, err)
+		}
+		res = app.do(t, fiber.MethodGet, "/api/movies/trash", nil).expect(t, fiber.StatusOK)
+		if trashed := decode[[]models.Movie](t, res.body.Data); len(trashed) != 0 {
+			t.Errorf("trash = %+v, want it empty", trashed)
+		}
+		app.do(t, fiber.MethodGet, alien, nil).expect(t, fiber.StatusOK)
+		heat := "/api/movies/" + strconv.Itoa(int(movies["Heat"].ID))
+		app.do(t, fiber.MethodPost, heat+"/restore", nil).expect(t, fiber.StatusNotFound)
+
+		// the purge is the last revision of the history
+		res = app.do(t, fiber.MethodGet, heat+"/history?per_page=1", nil).expect(t, fiber.StatusOK)
+		revisions := decode[[]models.MovieRevision](t, res.body.Data)
+		if len(revisions) != 1 || revisions[0].Action != models.RevisionPurge || revisions[0].Actor != "system:trash-purge" || revisions[0].Revision != 3 {
+			t.Fatalf("latest revision = %+v, want the purge at version 3", revisions)
+		}
+		diff := decode[map[string]models.FieldChange](t, json.RawMessage(revisions[0].Diff))
+		if diff["title"].From != "Heat" || diff["title"].To != nil {
+			t.Errorf("purge diff = %v, want every field to null", diff)
+		}
+	})
+}
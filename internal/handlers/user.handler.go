@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/middlewares"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+// ListUsers godoc
+// @Summary      List users
+// @Description  get a paginated list of user accounts
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        page      query     int     false  "Page number"  minimum(1)
+// @Param        per_page  query     int     false  "Users per page"  minimum(1)  maximum(100)
+// @Success      200  {object}  utils.SuccessResponse{data=[]models.User,meta=utils.Pagination} "Users fetched successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid query parameters"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to fetch users"
+// @Router       /api/users [get]
+func (h *Handler) ListUsers(ctx *fiber.Ctx) error {
+	// parse the query parameters
+	query := new(queries.PageQuery)
+	if err := ctx.QueryParser(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid query parameters", err.Error())
+	}
+
+	// validate the query parameters
+	if err := validators.ValidateStruct(query); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// fetch the requested page of users and count the users
+	users, total, err := h.Users.List(ctx.UserContext(), query)
+	if err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to fetch users", err.Error())
+	}
+
+	// return success response with users data and pagination metadata
+	pagination := utils.NewPagination(ctx, query.CurrentPage(), query.Size(), total)
+	return utils.PaginatedResponse(ctx, "Users fetched successfully", users, pagination)
+}
+
+// UpdateUserRole godoc
+// @Summary      Change a user role
+// @Description  grant a user the viewer, editor or admin role, it applies to access tokens issued afterwards
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id    path      string                  true  "User ID"
+// @Param        role  body      models.UserRoleRequest  true  "New role"
+// @Success      200  {object}  utils.SuccessResponse{data=models.User} "User role updated successfully"
+// @Failure      400  {object}  utils.ErrorResponse "Invalid request body or validation failed"
+// @Failure      401  {object}  utils.ErrorResponse "Authentication required"
+// @Failure      403  {object}  utils.ErrorResponse "Insufficient permissions"
+// @Failure      404  {object}  utils.ErrorResponse "User not found"
+// @Failure      409  {object}  utils.ErrorResponse "Admins cannot change their own role"
+// @Failure      500  {object}  utils.ErrorResponse "Failed to update user role"
+// @Router       /api/users/{id}/role [put]
+func (h *Handler) UpdateUserRole(ctx *fiber.Ctx) error {
+	// fetch the user by ID
+	user, err := h.Users.Get(ctx.UserContext(), paramID(ctx, "id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return utils.NotFoundResponse(ctx, "User not found", err.Error())
+		}
+		return utils.InternalServerErrorResponse(ctx, "Failed to update user role", err.Error())
+	}
+
+	// parse the request body
+	req := new(models.UserRoleRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		return utils.BadRequestResponse(ctx, "Invalid request body", err.Error())
+	}
+
+	// validate the new role
+	if err := validators.ValidateStruct(req); err != nil {
+		return utils.BadRequestResponse(ctx, "Validation failed", err)
+	}
+
+	// keep at least the acting admin around
+	if claims := middlewares.CurrentUser(ctx); claims != nil && user.ID == claims.UserID() && req.Role != user.Role {
+		return utils.ConflictResponse(ctx, "Admins cannot change their own role", "Ask another admin to change it")
+	}
+
+	// update the role
+	if err := h.Users.UpdateRole(ctx.UserContext(), user, req.Role); err != nil {
+		return utils.InternalServerErrorResponse(ctx, "Failed to update user role", err.Error())
+	}
+
+	// return success response with user data
+	return utils.OKResponse(ctx, "User role updated successfully", user)
+}
@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/shutdown"
+)
+
+// schedule runs the job right away and then at every interval, until
+// shutdown. Shutdown waits for a running job to finish.
+func schedule(name string, interval time.Duration, job func()) {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			job()
+
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	shutdown.Register(name+" job", func(ctx context.Context) error {
+		close(stop)
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
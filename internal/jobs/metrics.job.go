@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/handlers"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/metrics"
+)
+
+// StartMetricsRefresh periodically counts movies, genres, people and users
+// into their gauges, so that scrapes never query the database.
+func StartMetricsRefresh(config *config.Config, h *handlers.Handler) {
+	if !config.MetricsEnabled || config.MetricsRefreshInterval <= 0 {
+		return
+	}
+
+	schedule("metrics refresh", config.MetricsRefreshInterval, func() {
+		refreshMetrics(h)
+	})
+}
+
+func refreshMetrics(h *handlers.Handler) {
+	ctx := context.Background()
+
+	if active, err := h.Movies.Count(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to count movies")
+	} else {
+		metrics.MoviesTotal.WithLabelValues("active").Set(float64(active))
+	}
+	if trashed, err := h.Movies.Count(ctx, repositories.OnlyTrashed()); err != nil {
+		log.Error().Err(err).Msg("Failed to count trashed movies")
+	} else {
+		metrics.MoviesTotal.WithLabelValues("trashed").Set(float64(trashed))
+	}
+
+	if genres, err := h.Genres.List(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to count genres")
+	} else {
+		metrics.GenresTotal.Set(float64(len(genres)))
+	}
+
+	if people, err := h.People.Count(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to count people")
+	} else {
+		metrics.PeopleTotal.Set(float64(people))
+	}
+
+	roles, err := h.Users.CountByRole(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to count users")
+		return
+	}
+	for _, role := range []string{models.UserRoleViewer, models.UserRoleEditor, models.UserRoleAdmin} {
+		metrics.UsersTotal.WithLabelValues(role).Set(0)
+	}
+	for role, count := range roles {
+		metrics.UsersTotal.WithLabelValues(role).Set(float64(count))
+	}
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/handlers"
+)
+
+// tokenCleanupInterval is how often expired tokens are dropped.
+const tokenCleanupInterval = time.Hour
+
+// StartTokenCleanup periodically deletes expired refresh tokens and expired
+// entries of the access token revocation list, which no longer verify anyway.
+func StartTokenCleanup(h *handlers.Handler) {
+	schedule("token cleanup", tokenCleanupInterval, func() {
+		cleanupTokens(h)
+	})
+}
+
+func cleanupTokens(h *handlers.Handler) {
+	purged, err := h.Tokens.DeleteExpired(context.Background(), time.Now())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete expired tokens")
+	}
+
+	if purged > 0 {
+		log.Info().Int64("purged", purged).Msg("Deleted expired tokens")
+	}
+}
@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/handlers"
+)
+
+// StartTrashPurge periodically deletes movies that have been in the trash
+// longer than the configured retention. A zero retention keeps them forever.
+func StartTrashPurge(config *config.Config, h *handlers.Handler) {
+	if config.TrashRetention <= 0 || config.TrashPurgeInterval <= 0 {
+		log.Info().Msg("Trash purge disabled")
+		return
+	}
+
+	schedule("trash purge", config.TrashPurgeInterval, func() {
+		purgeTrash(h, config.TrashRetention)
+	})
+}
+
+// purgeTrash permanently deletes the movies trashed before the retention
+// window, together with their genre links, and records the purges in their
+// history. Credits cascade in the database.
+func purgeTrash(h *handlers.Handler, retention time.Duration) {
+	cutoff := time.Now().Add(-retention)
+
+	purged, err := h.PurgeTrash(context.Background(), cutoff)
+	if err != nil {
+		log.Error().Err(err).Int("purged", purged).Msg("Failed to purge trashed movies")
+		return
+	}
+
+	if purged > 0 {
+		log.Info().Int("purged", purged).Time("cutoff", cutoff).Msg("Purged trashed movies")
+	}
+}
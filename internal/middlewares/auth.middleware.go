@@ -0,0 +1,158 @@
+package middlewares
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/apikey"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/token"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+const (
+	// claimsKey is the Locals key holding the claims of the authenticated user.
+	claimsKey = "auth.claims"
+	// apiKeyKey is the Locals key holding the authenticated API key.
+	apiKeyKey = "auth.api_key"
+	// authFailedKey is the Locals key marking requests rejected for invalid credentials.
+	authFailedKey = "auth.failed"
+
+	// headerAPIKey is the alternative header for API keys.
+	headerAPIKey = "X-API-Key"
+
+	// lastUsedPrecision limits how often the last use of an API key is written.
+	lastUsedPrecision = time.Minute
+)
+
+// AuthMiddleware authenticates requests carrying a bearer access token or an
+// API key, in the Authorization or X-API-Key header. Requests without
+// credentials continue anonymously, invalid credentials are rejected.
+func AuthMiddleware(tokens repositories.TokenRepository, apiKeys repositories.APIKeyRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if key := c.Get(headerAPIKey); key != "" {
+			return authenticateAPIKey(c, apiKeys, key)
+		}
+
+		header := c.Get(fiber.HeaderAuthorization)
+		if header == "" {
+			return c.Next()
+		}
+
+		raw, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			return rejectCredentials(c, "Invalid authorization header", "Expected a Bearer token")
+		}
+		if strings.HasPrefix(raw, apikey.Scheme) {
+			return authenticateAPIKey(c, apiKeys, raw)
+		}
+
+		claims, err := token.Parse(raw, token.TypeAccess)
+		if err != nil {
+			return rejectCredentials(c, "Invalid access token", err.Error())
+		}
+
+		// reject access tokens revoked by a logout
+		revoked, err := tokens.AccessRevoked(c.UserContext(), claims.ID)
+		if err != nil {
+			return utils.InternalServerErrorResponse(c, "Failed to verify access token", err.Error())
+		}
+		if revoked {
+			return rejectCredentials(c, "Invalid access token", "The token has been revoked")
+		}
+
+		c.Locals(claimsKey, claims)
+		return c.Next()
+	}
+}
+
+// authenticateAPIKey looks the key up by its prefix and verifies its hash,
+// revocation and expiry.
+func authenticateAPIKey(c *fiber.Ctx, apiKeys repositories.APIKeyRepository, raw string) error {
+	prefix, ok := apikey.Prefix(raw)
+	if !ok {
+		return rejectCredentials(c, "Invalid API key", "Malformed API key")
+	}
+
+	key, err := apiKeys.GetActiveByPrefix(c.UserContext(), prefix)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return utils.InternalServerErrorResponse(c, "Failed to verify API key", err.Error())
+	}
+	if key == nil || !apikey.Matches(raw, key.KeyHash) {
+		return rejectCredentials(c, "Invalid API key", "Unknown or revoked API key")
+	}
+
+	now := time.Now()
+	if key.ExpiresAt != nil && key.ExpiresAt.Before(now) {
+		return rejectCredentials(c, "Invalid API key", "The API key has expired")
+	}
+
+	// record the use, at most once per precision window
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedPrecision {
+		if err := apiKeys.RecordUse(c.UserContext(), key, now); err != nil {
+			return utils.InternalServerErrorResponse(c, "Failed to verify API key", err.Error())
+		}
+	}
+
+	c.Locals(apiKeyKey, key)
+	return c.Next()
+}
+
+// RequirePermission only lets requests through whose user role or API key
+// scopes grant the permission.
+func RequirePermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if CurrentUser(c) == nil && CurrentAPIKey(c) == nil {
+			return unauthorized(c, "Authentication required", "Send an access token or an API key in the Authorization header")
+		}
+		if !HasPermission(c, permission) {
+			return utils.ForbiddenResponse(c, "Insufficient permissions", "Requires the "+permission+" permission")
+		}
+		return c.Next()
+	}
+}
+
+// HasPermission reports whether the authenticated user or API key has the permission.
+func HasPermission(c *fiber.Ctx, permission string) bool {
+	if claims := CurrentUser(c); claims != nil {
+		return slices.Contains(models.RolePermissions[claims.Role], permission)
+	}
+	if key := CurrentAPIKey(c); key != nil {
+		return slices.Contains(key.Scopes, permission)
+	}
+	return false
+}
+
+// CurrentUser returns the claims of the user authenticated with an access
+// token, nil for anonymous requests and API keys.
+func CurrentUser(c *fiber.Ctx) *token.Claims {
+	claims, _ := c.Locals(claimsKey).(*token.Claims)
+	return claims
+}
+
+// CurrentAPIKey returns the API key authenticating the request, nil otherwise.
+func CurrentAPIKey(c *fiber.Ctx) *models.APIKey {
+	key, _ := c.Locals(apiKeyKey).(*models.APIKey)
+	return key
+}
+
+// hasCredentials reports whether the request carries credentials AuthMiddleware checks.
+func hasCredentials(c *fiber.Ctx) bool {
+	return c.Get(headerAPIKey) != "" || c.Get(fiber.HeaderAuthorization) != ""
+}
+
+// rejectCredentials rejects invalid credentials, marking the request for
+// AuthFailureLimitMiddleware.
+func rejectCredentials(c *fiber.Ctx, message string, err interface{}) error {
+	c.Locals(authFailedKey, true)
+	return unauthorized(c, message, err)
+}
+
+func unauthorized(c *fiber.Ctx, message string, err interface{}) error {
+	c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+	return utils.UnauthorizedResponse(c, message, err)
+}
@@ -0,0 +1,148 @@
+package middlewares
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+	"github.com/zdacoder/go-fiber-movie-app-api/config/database"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/apikey"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/token"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newAuthTestDB returns a migrated in-memory SQLite database.
+func newAuthTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:?_pragma=foreign_keys(1)"), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection to an in-memory SQLite database opens a new one
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	// the migrations run on the database of the package
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+	if _, err := database.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// createAPIKey stores a new API key with the scopes and returns the plain key.
+func createAPIKey(t *testing.T, keys repositories.APIKeyRepository, key *models.APIKey) string {
+	t.Helper()
+	plain, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key.Name, key.Prefix, key.KeyHash = "integration", prefix, hash
+	if err := keys.Create(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+	return plain
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	token.Init(&config.Config{JWTSecret: "test-secret", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour})
+	db := newAuthTestDB(t)
+	tokens, keys := repositories.NewTokenRepository(db), repositories.NewAPIKeyRepository(db)
+	ctx := context.Background()
+
+	app := fiber.New()
+	app.Use(AuthMiddleware(tokens, keys))
+	app.Get("/", func(c *fiber.Ctx) error {
+		if CurrentUser(c) == nil && CurrentAPIKey(c) == nil {
+			return c.SendStatus(fiber.StatusNoContent)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Post("/", RequirePermission(models.PermissionMoviesWrite), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	send := func(method string, want int, headers ...string) {
+		t.Helper()
+		req := httptest.NewRequest(method, "/", nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != want {
+			t.Fatalf("%s with %q = %d, want %d", method, headers, resp.StatusCode, want)
+		}
+	}
+	bearer := func(raw string) []string {
+		return []string{fiber.HeaderAuthorization, "Bearer " + raw}
+	}
+
+	// anonymous requests continue, but not past the permission checks
+	send(fiber.MethodGet, fiber.StatusNoContent)
+	send(fiber.MethodPost, fiber.StatusUnauthorized)
+
+	// access tokens grant the permissions of the role of their user
+	viewer, _, err := token.Issue(1, models.UserRoleViewer, token.TypeAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	editor, editorClaims, err := token.Issue(2, models.UserRoleEditor, token.TypeAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send(fiber.MethodGet, fiber.StatusOK, bearer(viewer)...)
+	send(fiber.MethodPost, fiber.StatusForbidden, bearer(viewer)...)
+	send(fiber.MethodPost, fiber.StatusOK, bearer(editor)...)
+
+	// refresh tokens, forged and revoked access tokens are rejected
+	refresh, _, err := token.Issue(2, models.UserRoleEditor, token.TypeRefresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send(fiber.MethodGet, fiber.StatusUnauthorized, bearer(refresh)...)
+	send(fiber.MethodGet, fiber.StatusUnauthorized, bearer(editor+"x")...)
+	send(fiber.MethodGet, fiber.StatusUnauthorized, fiber.HeaderAuthorization, "Basic Zm9vOmJhcg==")
+	if err := tokens.RevokeAccess(ctx, &models.RevokedToken{JTI: editorClaims.ID, ExpiresAt: editorClaims.ExpiresAt.Time}); err != nil {
+		t.Fatal(err)
+	}
+	send(fiber.MethodGet, fiber.StatusUnauthorized, bearer(editor)...)
+
+	// API keys grant their scopes, in either header, and record their use
+	reader := createAPIKey(t, keys, &models.APIKey{Scopes: []string{models.PermissionMoviesRead}})
+	writer := createAPIKey(t, keys, &models.APIKey{Scopes: []string{models.PermissionMoviesRead, models.PermissionMoviesWrite}})
+	send(fiber.MethodGet, fiber.StatusOK, headerAPIKey, reader)
+	send(fiber.MethodPost, fiber.StatusForbidden, bearer(reader)...)
+	send(fiber.MethodPost, fiber.StatusOK, headerAPIKey, writer)
+	prefix, _ := apikey.Prefix(reader)
+	used, err := keys.GetActiveByPrefix(ctx, prefix)
+	if err != nil || used.LastUsedAt == nil {
+		t.Errorf("used API key = %+v, %v, want its last use recorded", used, err)
+	}
+
+	// unknown, malformed, expired and revoked API keys are rejected
+	send(fiber.MethodGet, fiber.StatusUnauthorized, headerAPIKey, prefix+"_wrong")
+	send(fiber.MethodGet, fiber.StatusUnauthorized, headerAPIKey, "mk_short")
+	expiresAt := time.Now().Add(-time.Minute)
+	expired := createAPIKey(t, keys, &models.APIKey{Scopes: []string{models.PermissionMoviesRead}, ExpiresAt: &expiresAt})
+	send(fiber.MethodGet, fiber.StatusUnauthorized, headerAPIKey, expired)
+	if err := keys.Revoke(ctx, used); err != nil {
+		t.Fatal(err)
+	}
+	send(fiber.MethodGet, fiber.StatusUnauthorized, headerAPIKey, reader)
+}
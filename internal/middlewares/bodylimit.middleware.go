@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// RouteBodyLimits returns a server hook giving routes, keyed by method and
+// path such as "POST /api/movies/import", a body limit of their own. It runs
+// once the headers are read, so that bodies over the limit are rejected with
+// 413 before being read. Other routes keep the body limit of the app.
+func RouteBodyLimits(limits map[string]int) func(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+	routes := make(map[string]int, len(limits))
+	for route, limit := range limits {
+		routes[routeKey(route)] = limit
+	}
+
+	return func(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+		path, _, _ := bytes.Cut(header.RequestURI(), []byte("?"))
+		return fasthttp.RequestConfig{MaxRequestBodySize: routes[routeKey(string(header.Method())+" "+string(path))]}
+	}
+}
+
+// routeKey normalizes a route like the router matches it, ignoring case and a
+// trailing slash.
+func routeKey(route string) string {
+	return strings.ToLower(strings.TrimSuffix(route, "/"))
+}
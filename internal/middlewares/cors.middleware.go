@@ -7,8 +7,9 @@ import (
 
 func CORSMiddleware() fiber.Handler {
 	return cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowMethods: "GET, POST, PUT, DELETE",
-		AllowHeaders: "Content-Type",
+		AllowOrigins:  "*",
+		AllowMethods:  "GET, POST, PUT, PATCH, DELETE",
+		AllowHeaders:  "Authorization, Content-Type, If-Match, If-None-Match, X-API-Key, X-Request-ID, traceparent, tracestate",
+		ExposeHeaders: "ETag, X-Request-ID, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After",
 	})
 }
@@ -4,9 +4,11 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/rs/zerolog/log"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/logger"
 )
 
+// LoggerMiddleware logs every request with the request logger, it must run
+// after RequestIDMiddleware.
 func LoggerMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		start := time.Now()
@@ -17,6 +19,8 @@ func LoggerMiddleware() fiber.Handler {
 		Status := c.Response().StatusCode()
 		IP := c.IP()
 
+		log := logger.Ctx(c.UserContext())
+
 		log.Info().
 			Str("method", Method).
 			Str("path", Path).
@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/metrics"
+)
+
+// unmatchedRoute labels requests that matched no route, so that unknown
+// paths do not create new series.
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware records the count, duration and status of requests by
+// route template, and the number of requests in flight.
+func MetricsMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		metrics.HTTPRequestsInFlight.Inc()
+		defer metrics.HTTPRequestsInFlight.Dec()
+
+		err := c.Next()
+
+		status := responseStatus(c, err)
+
+		// the method is backed by the request buffer, which is reused
+		labels := []string{utils.CopyString(c.Method()), routeTemplate(c, status), strconv.Itoa(status)}
+		metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+// responseStatus returns the status of the response. Errors returned by
+// handlers only become a status in the error handler, after middlewares ran.
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+// routeTemplate returns the template of the route that handled the request.
+// Requests answered by a middleware are labelled with its prefix, those
+// falling through to the not found handler as unmatched.
+func routeTemplate(c *fiber.Ctx, status int) string {
+	path := c.Route().Path
+	if path == "/" && status == fiber.StatusNotFound {
+		return unmatchedRoute
+	}
+	return path
+}
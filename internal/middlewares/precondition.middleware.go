@@ -0,0 +1,18 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+// PreconditionMiddleware rejects writes without an If-Match header when
+// strict conditional requests are configured.
+func PreconditionMiddleware(config *config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if config.RequireIfMatch && c.Get(fiber.HeaderIfMatch) == "" {
+			return utils.PreconditionRequiredResponse(c, "If-Match header is required", "Fetch the resource and send its ETag in the If-Match header")
+		}
+		return c.Next()
+	}
+}
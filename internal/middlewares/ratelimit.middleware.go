@@ -0,0 +1,125 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/health"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/logger"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/ratelimit"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/shutdown"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+)
+
+// NewRateLimitStore returns the configured token bucket store.
+func NewRateLimitStore(config *config.Config) ratelimit.Store {
+	if config.RateLimitStore != "redis" {
+		return ratelimit.NewMemoryStore()
+	}
+
+	options, err := redis.ParseURL(config.RedisURL)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid REDIS_URL")
+	}
+	log.Info().Str("addr", options.Addr).Msg("Rate limiting with Redis")
+
+	client := redis.NewClient(options)
+	health.Register("redis", func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	})
+	shutdown.Register("redis", func(context.Context) error {
+		return client.Close()
+	})
+	return ratelimit.NewRedisStore(client, "ratelimit:")
+}
+
+// RateLimitMiddleware limits requests with the token bucket of the route
+// group for the identity of the caller: its API key, its user or its IP.
+// It must run after AuthMiddleware. Store failures let requests through.
+func RateLimitMiddleware(config *config.Config, store ratelimit.Store, group string) fiber.Handler {
+	limits := config.RateLimits[group]
+
+	return func(c *fiber.Ctx) error {
+		if !config.RateLimitEnabled {
+			return c.Next()
+		}
+
+		identity, id := rateLimitIdentity(c)
+		limit := limits[identity]
+		if !limit.Enabled() {
+			return c.Next()
+		}
+
+		result, err := store.Take(c.UserContext(), group+":"+identity+":"+id, limit)
+		if err != nil {
+			logger.Ctx(c.UserContext()).Error().Err(err).Str("group", group).Msg("Rate limit store failed, allowing request")
+			return c.Next()
+		}
+
+		c.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, int(limit.Period.Seconds())))
+		c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+
+		if !result.Allowed {
+			c.Set(fiber.HeaderRetryAfter, ceilSeconds(result.RetryAfter))
+			return utils.TooManyRequestsResponse(c, "Rate limit exceeded", fmt.Sprintf("Limit of %s requests reached, retry later", limit))
+		}
+		return c.Next()
+	}
+}
+
+// AuthFailureLimitMiddleware limits by IP the requests rejected for invalid
+// credentials, which each cost a lookup and never reach the limits of an
+// identity. It must run before AuthMiddleware: IPs out of tokens are refused
+// before their credentials are looked up, anonymous requests are not.
+// Store failures let requests through.
+func AuthFailureLimitMiddleware(config *config.Config, store ratelimit.Store) fiber.Handler {
+	limit := config.RateLimits[ratelimit.GroupAuthFailure][ratelimit.IdentityIP]
+
+	return func(c *fiber.Ctx) error {
+		if !config.RateLimitEnabled || !limit.Enabled() || !hasCredentials(c) {
+			return c.Next()
+		}
+
+		key := ratelimit.GroupAuthFailure + ":" + ratelimit.IdentityIP + ":" + c.IP()
+		result, err := store.Peek(c.UserContext(), key, limit)
+		if err != nil {
+			logger.Ctx(c.UserContext()).Error().Err(err).Str("group", ratelimit.GroupAuthFailure).Msg("Rate limit store failed, allowing request")
+		} else if !result.Allowed {
+			c.Set(fiber.HeaderRetryAfter, ceilSeconds(result.RetryAfter))
+			return utils.TooManyRequestsResponse(c, "Too many failed authentications", fmt.Sprintf("Limit of %s failed authentications reached, retry later", limit))
+		}
+
+		// only the rejected credentials take a token
+		err = c.Next()
+		if failed, _ := c.Locals(authFailedKey).(bool); failed {
+			if _, err := store.Take(c.UserContext(), key, limit); err != nil {
+				logger.Ctx(c.UserContext()).Error().Err(err).Str("group", ratelimit.GroupAuthFailure).Msg("Rate limit store failed, allowing request")
+			}
+		}
+		return err
+	}
+}
+
+// rateLimitIdentity returns the identity type and ID the request is limited by.
+func rateLimitIdentity(c *fiber.Ctx) (string, string) {
+	if key := CurrentAPIKey(c); key != nil {
+		return ratelimit.IdentityAPIKey, key.Prefix
+	}
+	if claims := CurrentUser(c); claims != nil {
+		return ratelimit.IdentityUser, claims.Subject
+	}
+	return ratelimit.IdentityIP, c.IP()
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
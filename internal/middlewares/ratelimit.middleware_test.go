@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/ratelimit"
+)
+
+func TestAuthFailureLimitMiddleware(t *testing.T) {
+	cfg := &config.Config{
+		RateLimitEnabled: true,
+		RateLimits: map[string]map[string]ratelimit.Limit{
+			ratelimit.GroupAuthFailure: {ratelimit.IdentityIP: {Requests: 2, Period: time.Minute}},
+		},
+	}
+	app := fiber.New()
+	app.Use(AuthFailureLimitMiddleware(cfg, ratelimit.NewMemoryStore()))
+	app.Use(AuthMiddleware(nil, nil))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	get := func(authorization string, want int) {
+		t.Helper()
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		if authorization != "" {
+			req.Header.Set(fiber.HeaderAuthorization, authorization)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != want {
+			t.Fatalf("GET with %q = %d, want %d", authorization, resp.StatusCode, want)
+		}
+	}
+
+	// anonymous requests do not count, invalid credentials do until the limit
+	get("", fiber.StatusOK)
+	get("Basic Zm9vOmJhcg==", fiber.StatusUnauthorized)
+	get("", fiber.StatusOK)
+	get("Bearer forged", fiber.StatusUnauthorized)
+	get("Bearer forged", fiber.StatusTooManyRequests)
+	get("", fiber.StatusOK)
+}
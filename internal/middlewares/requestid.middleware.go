@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"regexp"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/logger"
+)
+
+// requestIDPattern restricts accepted request IDs to safe, bounded values.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDMiddleware accepts the X-Request-ID of the caller or generates
+// one, echoes it in the response and attaches a logger carrying it to the
+// user context of the request.
+func RequestIDMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(fiber.HeaderXRequestID)
+		if !requestIDPattern.MatchString(id) {
+			id = utils.UUIDv4()
+		}
+
+		c.Set(fiber.HeaderXRequestID, id)
+		c.SetUserContext(logger.WithRequest(c.UserContext(), id))
+		return c.Next()
+	}
+}
+
+// RequestID returns the ID of the request.
+func RequestID(c *fiber.Ctx) string {
+	return logger.RequestID(c.UserContext())
+}
@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/logger"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware starts a server span for every request, continuing the
+// trace of the W3C traceparent header when there is one, and puts it in the
+// user context so that database queries become its children. It must run
+// before RequestIDMiddleware for logs to carry the trace ID.
+func TracingMiddleware() fiber.Handler {
+	tracer := telemetry.Tracer()
+
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{c})
+
+		// request values are backed by buffers that are reused after the request
+		method := utils.CopyString(c.Method())
+		ctx, span := tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.URLPath(utils.CopyString(c.Path())),
+			semconv.URLScheme(c.Protocol()),
+			semconv.ClientAddress(c.IP()),
+			semconv.UserAgentOriginal(utils.CopyString(c.Get(fiber.HeaderUserAgent))),
+		))
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		route := routeTemplate(c, status)
+		span.SetName(method + " " + route)
+		span.SetAttributes(
+			semconv.HTTPRoute(route),
+			semconv.HTTPResponseStatusCode(status),
+			attribute.String("request.id", logger.RequestID(c.UserContext())),
+		)
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+			if err != nil {
+				span.RecordError(err)
+			}
+		}
+
+		return err
+	}
+}
+
+// headerCarrier adapts the request and response headers to the propagators.
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string {
+	return utils.CopyString(h.c.Get(key))
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h.c.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	headers := h.c.GetReqHeaders()
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	return keys
+}
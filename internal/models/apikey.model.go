@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	PermissionMoviesRead  = "movies:read"
+	PermissionMoviesWrite = "movies:write"
+	PermissionAdmin       = "admin"
+)
+
+// RolePermissions are the permissions granted to users by role, API keys are
+// granted the same permissions as scopes.
+var RolePermissions = map[string][]string{
+	UserRoleViewer: {PermissionMoviesRead},
+	UserRoleEditor: {PermissionMoviesRead, PermissionMoviesWrite},
+	UserRoleAdmin:  {PermissionMoviesRead, PermissionMoviesWrite, PermissionAdmin},
+}
+
+// APIKey authenticates server-to-server integrations. Only the SHA-256 hash
+// of the key is stored, the prefix identifies it in listings and logs.
+type APIKey struct {
+	ID          uint                        `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string                      `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix      string                      `gorm:"type:varchar(16);not null;uniqueIndex" json:"prefix"`
+	KeyHash     string                      `gorm:"type:varchar(64);not null" json:"-"`
+	Scopes      datatypes.JSONSlice[string] `gorm:"not null" json:"scopes" swaggertype:"array,string"`
+	ExpiresAt   *time.Time                  `json:"expires_at"`
+	LastUsedAt  *time.Time                  `json:"last_used_at"`
+	RevokedAt   *time.Time                  `json:"revoked_at"`
+	CreatedByID *uint                       `json:"created_by_id"`
+	CreatedBy   *User                       `gorm:"constraint:OnDelete:SET NULL" json:"-"`
+	CreatedAt   time.Time                   `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type APIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=movies:read movies:write admin"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreatedAPIKey is returned once when a key is created, with the plain key.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
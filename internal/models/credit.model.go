@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	RoleDirector        = "director"
+	RoleActor           = "actor"
+	RoleWriter          = "writer"
+	RoleComposer        = "composer"
+	RoleProducer        = "producer"
+	RoleCinematographer = "cinematographer"
+	RoleEditor          = "editor"
+)
+
+type Credit struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	MovieID       uint      `gorm:"not null;index" json:"movie_id"`
+	PersonID      uint      `gorm:"not null;index" json:"person_id" validate:"required"`
+	Role          string    `gorm:"type:varchar(50);not null" json:"role" validate:"required,oneof=director actor writer composer producer cinematographer editor"`
+	CharacterName string    `gorm:"type:varchar(255)" json:"character_name,omitempty" validate:"omitempty,max=255"`
+	BillingOrder  int       `gorm:"not null;default:0" json:"billing_order" validate:"omitempty,min=0"`
+	Movie         *Movie    `gorm:"constraint:OnDelete:CASCADE" json:"movie,omitempty" validate:"-"`
+	Person        *Person   `gorm:"constraint:OnDelete:RESTRICT" json:"person,omitempty" validate:"-"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
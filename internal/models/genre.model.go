@@ -0,0 +1,33 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Genre struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"type:varchar(100);not null" json:"name" validate:"required,max=100"`
+	Slug      string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"slug" validate:"omitempty,max=100,slug"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// UnmarshalJSON accepts a genre object, a slug or an ID,
+// so movie payloads can reference genres as ["drama", 3].
+func (g *Genre) UnmarshalJSON(data []byte) error {
+	var slug string
+	if err := json.Unmarshal(data, &slug); err == nil {
+		*g = Genre{Slug: slug}
+		return nil
+	}
+
+	var id uint
+	if err := json.Unmarshal(data, &id); err == nil {
+		*g = Genre{ID: id}
+		return nil
+	}
+
+	type genre Genre
+	return json.Unmarshal(data, (*genre)(g))
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatJSON   = "json"
+	ImportFormatNDJSON = "ndjson"
+)
+
+const (
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+const (
+	ImportRowCreated = "created"
+	ImportRowUpdated = "updated"
+	ImportRowFailed  = "failed"
+)
+
+// ImportJob is a bulk movie import and the report of its rows. Small imports
+// complete within their request, large ones run in the background and are
+// polled until they are no longer running. Dry runs report what would happen
+// without writing any movie.
+type ImportJob struct {
+	ID         uint                           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Status     string                         `gorm:"type:varchar(20);not null" json:"status" example:"completed"`
+	Format     string                         `gorm:"type:varchar(10);not null" json:"format" example:"csv"`
+	DryRun     bool                           `gorm:"not null" json:"dry_run"`
+	Upsert     bool                           `gorm:"not null" json:"upsert"`
+	Actor      string                         `gorm:"type:varchar(255);not null" json:"actor" example:"user:1"`
+	Total      int                            `gorm:"not null" json:"total" example:"3"`
+	Processed  int                            `gorm:"not null" json:"processed" example:"3"`
+	Created    int                            `gorm:"not null" json:"created" example:"1"`
+	Updated    int                            `gorm:"not null" json:"updated" example:"1"`
+	Failed     int                            `gorm:"not null" json:"failed" example:"1"`
+	Report     datatypes.JSONSlice[ImportRow] `gorm:"not null" json:"report" swaggertype:"array,object"`
+	Error      string                         `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt  time.Time                      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time                      `gorm:"autoUpdateTime" json:"updated_at"`
+	FinishedAt *time.Time                     `json:"finished_at"`
+}
+
+// ImportRow is the outcome of one row of an import. Rows are numbered like
+// the lines of CSV and NDJSON files, the CSV header being row 1, and from 1
+// in JSON arrays.
+type ImportRow struct {
+	Row     int      `json:"row" example:"2"`
+	Status  string   `json:"status" example:"failed"`
+	MovieID uint     `json:"movie_id,omitempty" example:"12"`
+	Title   string   `json:"title,omitempty" example:"Inception"`
+	Errors  []string `json:"errors,omitempty" example:"Rating: This field is required"`
+}
@@ -1,21 +1,58 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
-	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 type Movie struct {
 	ID              uint           `gorm:"primaryKey;autoIncrement" json:"id"`
-	Title           string         `gorm:"type:varchar(255);not null" json:"title" validate:"required"`
+	Title           string         `gorm:"type:varchar(255);not null;index:idx_movies_title_trgm,type:gin,expression:title gin_trgm_ops" json:"title" validate:"required"`
 	Description     string         `gorm:"type:text;not null" json:"description" validate:"required"`
 	PosterURL       string         `gorm:"type:varchar(255);not null" json:"poster_url" validate:"required,url"`
 	ReleaseDate     string         `gorm:"type:date;not null" json:"release_date" validate:"required,datetime=2006-01-02"`
 	Rating          float64        `gorm:"type:decimal(3,1);not null" json:"rating" validate:"required,numeric"`
 	DurationMinutes int            `gorm:"type:int;not null" json:"duration_minutes" validate:"required,numeric"`
-	Director        string         `gorm:"type:varchar(255);not null" json:"director" validate:"required"`
-	Genre           datatypes.JSON `gorm:"type:json;not null" json:"genre" validate:"required,min=1,dive"`
+	Director        string         `gorm:"type:varchar(255);not null;index:idx_movies_director_trgm,type:gin,expression:director gin_trgm_ops" json:"director" validate:"required"`
+	Genres          []Genre        `gorm:"many2many:movie_genres" json:"genres" validate:"required,min=1"`
+	Version         uint           `gorm:"not null;default:1" json:"version"`
 	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at" swaggertype:"string" format:"date-time"`
+
+	// SearchVector is maintained by PostgreSQL, title ranks above director and description
+	SearchVector string `gorm:"->:false;<-:false;type:tsvector GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(director, '')), 'B') || setweight(to_tsvector('english', coalesce(description, '')), 'C')) STORED;index:idx_movies_search_vector,type:gin" json:"-" swaggerignore:"true"`
+}
+
+// ETag returns the strong entity tag of the movie, which changes with every version.
+func (m *Movie) ETag() string {
+	return fmt.Sprintf(`"%d-%d"`, m.ID, m.Version)
+}
+
+// MoviePatchFields maps the JSON fields of a movie that clients may patch to their struct fields
+var MoviePatchFields = map[string]string{
+	"title":            "Title",
+	"description":      "Description",
+	"poster_url":       "PosterURL",
+	"release_date":     "ReleaseDate",
+	"rating":           "Rating",
+	"duration_minutes": "DurationMinutes",
+	"director":         "Director",
+	"genres":           "Genres",
+}
+
+type MovieSearchResult struct {
+	Movie
+	Rank                 float64 `gorm:"column:search_rank" json:"rank" example:"0.6"`
+	TitleHighlight       string  `json:"title_highlight" example:"<mark>Inception</mark>"`
+	DescriptionHighlight string  `json:"description_highlight" example:"A mind-bending thriller about <mark>dreams</mark> within <mark>dreams</mark>."`
+}
+
+type MovieSuggestion struct {
+	ID          uint   `json:"id" example:"1"`
+	Title       string `json:"title" example:"The Godfather"`
+	ReleaseYear int    `json:"release_year" example:"1972"`
+	PosterURL   string `json:"poster_url" example:"https://example.com/godfather.jpg"`
 }
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type Person struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"type:varchar(255);not null;index:idx_people_name_lower,expression:lower(name)" json:"name" validate:"required,max=255"`
+	Biography string    `gorm:"type:text" json:"biography"`
+	BirthDate *string   `gorm:"type:date" json:"birth_date" validate:"omitempty,datetime=2006-01-02"`
+	PhotoURL  string    `gorm:"type:varchar(255)" json:"photo_url" validate:"omitempty,url"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type Filmography struct {
+	Person  Person   `json:"person"`
+	Credits []Credit `json:"credits"`
+}
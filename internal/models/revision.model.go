@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionDelete  = "delete"
+	RevisionRestore = "restore"
+	RevisionRevert  = "revert"
+	RevisionPurge   = "purge"
+)
+
+// MovieRevision is an audit entry written for every change to a movie. The
+// revision number is the movie version the change produced, and the entries
+// outlive the movie so purged movies keep their history.
+type MovieRevision struct {
+	ID        uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	MovieID   uint           `gorm:"not null;uniqueIndex:idx_movie_revisions_movie_revision" json:"movie_id"`
+	Revision  uint           `gorm:"not null;uniqueIndex:idx_movie_revisions_movie_revision" json:"revision"`
+	Action    string         `gorm:"type:varchar(20);not null" json:"action"`
+	Actor     string         `gorm:"type:varchar(255);not null" json:"actor"`
+	RequestID string         `gorm:"type:varchar(100)" json:"request_id,omitempty"`
+	Diff      datatypes.JSON `gorm:"not null" json:"diff" swaggertype:"object"`
+	Snapshot  datatypes.JSON `gorm:"not null" json:"snapshot" swaggertype:"object"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// FieldChange is the previous and new value of one movie field in a revision diff.
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
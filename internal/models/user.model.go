@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/token"
+)
+
+const (
+	UserRoleViewer = "viewer"
+	UserRoleEditor = "editor"
+	UserRoleAdmin  = "admin"
+)
+
+type User struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Email        string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"email"`
+	Name         string    `gorm:"type:varchar(255);not null" json:"name"`
+	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"`
+	Role         string    `gorm:"type:varchar(20);not null;default:viewer" json:"role"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// RefreshToken tracks an issued refresh token so it can be rotated once and
+// revoked. Presenting a rotated token again revokes every token of the user.
+type RefreshToken struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `gorm:"not null;index"`
+	JTI       string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	RevokedAt *time.Time
+	User      *User     `gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// RevokedToken is the revocation list of access tokens, entries can be
+// dropped once the token has expired.
+type RevokedToken struct {
+	JTI       string    `gorm:"type:varchar(64);primaryKey"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+type RegisterRequest struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Name     string `json:"name" validate:"required,max=255"`
+	Password string `json:"password" validate:"required,min=8,maxbytes=72"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type UserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=viewer editor admin"`
+}
+
+// AuthSession is returned when a user registers, logs in or refreshes tokens.
+type AuthSession struct {
+	User   *User       `json:"user"`
+	Tokens *token.Pair `json:"tokens"`
+}
@@ -0,0 +1,103 @@
+package queries
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/cursor"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DecodeCursor returns the cursor sent by the client, or nil for the first page.
+// A cursor issued for another sort order is rejected.
+func (q *MovieListQuery) DecodeCursor() (*cursor.Cursor, error) {
+	if q.Cursor == "" {
+		return nil, nil
+	}
+
+	c, err := cursor.Decode(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if c.Sort != q.SortColumn() || c.Desc != q.Descending() {
+		return nil, cursor.ErrInvalidCursor
+	}
+	if _, err := keysetValue(c.Sort, c.Value); err != nil {
+		return nil, cursor.ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Keyset returns a gorm scope seeking past the cursor position.
+// One extra row is fetched so callers can tell whether another page exists.
+func (q *MovieListQuery) Keyset(c *cursor.Cursor) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		column := q.SortColumn()
+		desc := q.Descending()
+		if c != nil && c.Backward {
+			desc = !desc
+		}
+
+		if c != nil {
+			value, _ := keysetValue(column, c.Value)
+			operator := ">"
+			if desc {
+				operator = "<"
+			}
+			db = db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, operator), value, c.ID)
+		}
+
+		return db.
+			Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc}).
+			Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: desc}).
+			Limit(q.Size() + 1)
+	}
+}
+
+// CursorFor builds the cursor pointing at the given movie.
+func (q *MovieListQuery) CursorFor(movie *models.Movie, backward bool) string {
+	return cursor.Encode(cursor.Cursor{
+		Sort:     q.SortColumn(),
+		Desc:     q.Descending(),
+		Value:    movieSortValue(movie, q.SortColumn()),
+		ID:       movie.ID,
+		Backward: backward,
+	})
+}
+
+func movieSortValue(movie *models.Movie, column string) string {
+	switch column {
+	case "title":
+		return movie.Title
+	case "release_date":
+		return movie.ReleaseDate
+	case "rating":
+		return strconv.FormatFloat(movie.Rating, 'f', -1, 64)
+	case "duration_minutes":
+		return strconv.Itoa(movie.DurationMinutes)
+	default:
+		return movie.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+// keysetValue converts a cursor value back to the type of its column.
+func keysetValue(column, value string) (interface{}, error) {
+	switch column {
+	case "title":
+		return value, nil
+	case "release_date":
+		if len(value) < len(time.DateOnly) {
+			return nil, cursor.ErrInvalidCursor
+		}
+		return time.Parse(time.DateOnly, value[:len(time.DateOnly)])
+	case "rating":
+		return strconv.ParseFloat(value, 64)
+	case "duration_minutes":
+		return strconv.Atoi(value)
+	default:
+		return time.Parse(time.RFC3339Nano, value)
+	}
+}
@@ -0,0 +1,166 @@
+package queries
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/config/database"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// movieSortColumns whitelists the columns movies can be sorted by.
+var movieSortColumns = map[string]string{
+	"title":            "title",
+	"release_date":     "release_date",
+	"rating":           "rating",
+	"duration_minutes": "duration_minutes",
+	"created_at":       "created_at",
+}
+
+// MovieListQuery holds the pagination, sorting and filtering parameters of the movie list.
+type MovieListQuery struct {
+	PageQuery
+	MovieFilterQuery
+
+	Cursor string `query:"cursor"`
+
+	Sort  string `query:"sort" validate:"omitempty,oneof=title release_date rating duration_minutes created_at"`
+	Order string `query:"order" validate:"omitempty,oneof=asc desc"`
+}
+
+// MovieFilterQuery holds the movie filters shared by the list and the export.
+type MovieFilterQuery struct {
+	Director    string  `query:"director"`
+	Genre       string  `query:"genre"`
+	YearFrom    int     `query:"year_from" validate:"omitempty,min=1800,max=9999"`
+	YearTo      int     `query:"year_to" validate:"omitempty,min=1800,max=9999,gtefield=YearFrom"`
+	RatingMin   float64 `query:"rating_min" validate:"omitempty,min=0,max=10"`
+	RatingMax   float64 `query:"rating_max" validate:"omitempty,min=0,max=10,gtefield=RatingMin"`
+	DurationMin int     `query:"duration_min" validate:"omitempty,min=1"`
+	DurationMax int     `query:"duration_max" validate:"omitempty,min=1,gtefield=DurationMin"`
+}
+
+// MovieSearchQuery holds the parameters of the movie full-text search.
+type MovieSearchQuery struct {
+	PageQuery
+
+	Q string `query:"q" validate:"required,min=2,max=200"`
+}
+
+// MovieAutocompleteQuery holds the parameters of the movie title suggestions.
+type MovieAutocompleteQuery struct {
+	Q     string `query:"q" validate:"required,max=100"`
+	Limit int    `query:"limit" validate:"omitempty,min=1,max=20"`
+}
+
+// Size returns the number of suggestions to return.
+func (q *MovieAutocompleteQuery) Size() int {
+	if q.Limit > 0 {
+		return q.Limit
+	}
+	return 10
+}
+
+// MovieImportQuery holds the options of a movie import. The format defaults
+// to the one of the uploaded file.
+type MovieImportQuery struct {
+	Format string `query:"format" validate:"omitempty,oneof=csv json ndjson"`
+	DryRun bool   `query:"dry_run"`
+	Upsert bool   `query:"upsert"`
+}
+
+// MovieExportColumns lists the columns movies can be exported with, in their default order.
+var MovieExportColumns = []string{"id", "title", "description", "poster_url", "release_date", "rating", "duration_minutes", "director", "genres", "version", "created_at", "updated_at"}
+
+// MovieExportQuery holds the parameters of the movie export.
+type MovieExportQuery struct {
+	MovieFilterQuery
+
+	Format  string `query:"format" validate:"omitempty,oneof=csv ndjson json"`
+	Columns string `query:"columns"`
+	Gzip    bool   `query:"gzip"`
+}
+
+// ExportFormat returns the requested format, CSV by default.
+func (q *MovieExportQuery) ExportFormat() string {
+	if q.Format == "" {
+		return "csv"
+	}
+	return q.Format
+}
+
+// SelectedColumns returns the requested comma-separated columns, every one by default.
+func (q *MovieExportQuery) SelectedColumns() ([]string, error) {
+	if strings.TrimSpace(q.Columns) == "" {
+		return MovieExportColumns, nil
+	}
+
+	var columns []string
+	for _, column := range strings.Split(q.Columns, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !slices.Contains(MovieExportColumns, column) {
+			return nil, fmt.Errorf("unknown column %q, expected some of %s", column, strings.Join(MovieExportColumns, ", "))
+		}
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns, nil
+}
+
+// SortColumn returns the whitelisted column to sort by.
+func (q *MovieListQuery) SortColumn() string {
+	if column, ok := movieSortColumns[q.Sort]; ok {
+		return column
+	}
+	return "created_at"
+}
+
+// Descending reports whether results are sorted in descending order.
+func (q *MovieListQuery) Descending() bool {
+	if q.Order == "" {
+		return q.Sort == "" || q.Sort == "created_at"
+	}
+	return q.Order == "desc"
+}
+
+// Filter is a gorm scope applying the movie filters.
+func (q *MovieFilterQuery) Filter(db *gorm.DB) *gorm.DB {
+	if q.Director != "" {
+		db = db.Where(database.ILike(db, "movies.director"), "%"+strings.TrimSpace(q.Director)+"%")
+	}
+	if q.Genre != "" {
+		db = db.Where("EXISTS (SELECT 1 FROM movie_genres JOIN genres ON genres.id = movie_genres.genre_id "+
+			"WHERE movie_genres.movie_id = movies.id AND genres.slug = ?)", utils.Slugify(q.Genre))
+	}
+	if q.YearFrom > 0 {
+		db = db.Where("release_date >= ?", fmt.Sprintf("%04d-01-01", q.YearFrom))
+	}
+	if q.YearTo > 0 {
+		db = db.Where("release_date <= ?", fmt.Sprintf("%04d-12-31", q.YearTo))
+	}
+	if q.RatingMin > 0 {
+		db = db.Where("rating >= ?", q.RatingMin)
+	}
+	if q.RatingMax > 0 {
+		db = db.Where("rating <= ?", q.RatingMax)
+	}
+	if q.DurationMin > 0 {
+		db = db.Where("duration_minutes >= ?", q.DurationMin)
+	}
+	if q.DurationMax > 0 {
+		db = db.Where("duration_minutes <= ?", q.DurationMax)
+	}
+	return db
+}
+
+// SortBy is a gorm scope ordering by the requested column, using the ID as tie-breaker.
+func (q *MovieListQuery) SortBy(db *gorm.DB) *gorm.DB {
+	desc := q.Descending()
+	return db.
+		Order(clause.OrderByColumn{Column: clause.Column{Name: q.SortColumn()}, Desc: desc}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: desc})
+}
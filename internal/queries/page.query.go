@@ -0,0 +1,53 @@
+package queries
+
+import "gorm.io/gorm"
+
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+// PageQuery holds the offset pagination parameters shared by list endpoints.
+// Clients may send either page/per_page or limit/offset.
+type PageQuery struct {
+	Page    int `query:"page" validate:"omitempty,min=1"`
+	PerPage int `query:"per_page" validate:"omitempty,min=1,max=100"`
+	Limit   int `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset  int `query:"offset" validate:"omitempty,min=0"`
+}
+
+// Size returns the number of items per page.
+func (q *PageQuery) Size() int {
+	switch {
+	case q.Limit > 0:
+		return q.Limit
+	case q.PerPage > 0:
+		return q.PerPage
+	default:
+		return DefaultPerPage
+	}
+}
+
+// Skip returns the number of items to skip.
+func (q *PageQuery) Skip() int {
+	if q.Offset > 0 {
+		return q.Offset
+	}
+	return (q.CurrentPage() - 1) * q.Size()
+}
+
+// CurrentPage returns the requested page, derived from offset when given.
+func (q *PageQuery) CurrentPage() int {
+	if q.Offset > 0 {
+		return q.Offset/q.Size() + 1
+	}
+	if q.Page > 0 {
+		return q.Page
+	}
+	return 1
+}
+
+// Paginate is a gorm scope applying the limit and offset.
+func (q *PageQuery) Paginate(db *gorm.DB) *gorm.DB {
+	return db.Offset(q.Skip()).Limit(q.Size())
+}
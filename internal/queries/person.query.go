@@ -0,0 +1,24 @@
+package queries
+
+import (
+	"strings"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/config/database"
+
+	"gorm.io/gorm"
+)
+
+// PersonListQuery holds the pagination and name filter of the people list.
+type PersonListQuery struct {
+	PageQuery
+
+	Q string `query:"q" validate:"omitempty,max=255"`
+}
+
+// Filter is a gorm scope matching people by partial name.
+func (q *PersonListQuery) Filter(db *gorm.DB) *gorm.DB {
+	if q.Q != "" {
+		db = db.Where(database.ILike(db, "name"), "%"+strings.TrimSpace(q.Q)+"%")
+	}
+	return db
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository stores the API keys of server-to-server integrations.
+type APIKeyRepository interface {
+	// List returns a page of the API keys, newest first, and their total.
+	List(ctx context.Context, page *queries.PageQuery) ([]models.APIKey, int64, error)
+	Get(ctx context.Context, id uint) (*models.APIKey, error)
+	// GetActiveByPrefix returns the API key with the prefix unless it was revoked.
+	GetActiveByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	Create(ctx context.Context, key *models.APIKey) error
+	// Revoke revokes the API key, keeping the time of an earlier revocation.
+	Revoke(ctx context.Context, key *models.APIKey) error
+	// RecordUse sets the time the API key was last used.
+	RecordUse(ctx context.Context, key *models.APIKey, at time.Time) error
+}
+
+type gormAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository returns an APIKeyRepository backed by the database.
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &gormAPIKeyRepository{db: db}
+}
+
+func (r *gormAPIKeyRepository) List(ctx context.Context, page *queries.PageQuery) ([]models.APIKey, int64, error) {
+	db := Conn(ctx, r.db)
+
+	var total int64
+	if err := db.Model(&models.APIKey{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	keys := []models.APIKey{}
+	if err := db.Scopes(page.Paginate).Order("created_at DESC").Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, 0, err
+	}
+	return keys, total, nil
+}
+
+func (r *gormAPIKeyRepository) Get(ctx context.Context, id uint) (*models.APIKey, error) {
+	key := new(models.APIKey)
+	if err := Conn(ctx, r.db).First(key, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return key, nil
+}
+
+func (r *gormAPIKeyRepository) GetActiveByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	key := new(models.APIKey)
+	if err := Conn(ctx, r.db).Where("prefix = ? AND revoked_at IS NULL", prefix).First(key).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return key, nil
+}
+
+func (r *gormAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	return Conn(ctx, r.db).Create(key).Error
+}
+
+func (r *gormAPIKeyRepository) Revoke(ctx context.Context, key *models.APIKey) error {
+	if key.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	if err := Conn(ctx, r.db).Model(key).Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	key.RevokedAt = &now
+	return nil
+}
+
+func (r *gormAPIKeyRepository) RecordUse(ctx context.Context, key *models.APIKey, at time.Time) error {
+	if err := Conn(ctx, r.db).Model(key).UpdateColumn("last_used_at", at).Error; err != nil {
+		return err
+	}
+	key.LastUsedAt = &at
+	return nil
+}
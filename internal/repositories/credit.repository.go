@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// CreditRepository stores the cast and crew of movies.
+type CreditRepository interface {
+	// ListByMovie returns the credits of a movie with the credited people,
+	// ordered by role and billing order.
+	ListByMovie(ctx context.Context, movieID uint) ([]models.Credit, error)
+	// ListByPerson returns the credits of a person with the credited movies
+	// and their genres, newest movie first, leaving out trashed movies.
+	ListByPerson(ctx context.Context, personID uint) ([]models.Credit, error)
+	// CountByPerson returns the number of credits of a person, on trashed movies too.
+	CountByPerson(ctx context.Context, personID uint) (int64, error)
+	// Get returns a credit of the movie.
+	Get(ctx context.Context, movieID, id uint) (*models.Credit, error)
+	Create(ctx context.Context, credit *models.Credit) error
+	Delete(ctx context.Context, credit *models.Credit) error
+}
+
+type gormCreditRepository struct {
+	db *gorm.DB
+}
+
+// NewCreditRepository returns a CreditRepository backed by the database.
+func NewCreditRepository(db *gorm.DB) CreditRepository {
+	return &gormCreditRepository{db: db}
+}
+
+func (r *gormCreditRepository) ListByMovie(ctx context.Context, movieID uint) ([]models.Credit, error) {
+	credits := []models.Credit{}
+	err := Conn(ctx, r.db).
+		Joins("Person").
+		Where("credits.movie_id = ?", movieID).
+		Order("credits.role").
+		Order("credits.billing_order").
+		Order("credits.id").
+		Find(&credits).Error
+	if err != nil {
+		return nil, err
+	}
+	return credits, nil
+}
+
+func (r *gormCreditRepository) ListByPerson(ctx context.Context, personID uint) ([]models.Credit, error) {
+	credits := []models.Credit{}
+	err := Conn(ctx, r.db).
+		Joins("JOIN movies ON movies.id = credits.movie_id AND movies.deleted_at IS NULL").
+		Preload("Movie.Genres").
+		Where("credits.person_id = ?", personID).
+		Order("movies.release_date DESC").
+		Order("credits.billing_order").
+		Find(&credits).Error
+	if err != nil {
+		return nil, err
+	}
+	return credits, nil
+}
+
+func (r *gormCreditRepository) CountByPerson(ctx context.Context, personID uint) (int64, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.Credit{}).Where("person_id = ?", personID).Count(&count).Error
+	return count, err
+}
+
+func (r *gormCreditRepository) Get(ctx context.Context, movieID, id uint) (*models.Credit, error) {
+	credit := new(models.Credit)
+	if err := Conn(ctx, r.db).Where("movie_id = ?", movieID).First(credit, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return credit, nil
+}
+
+func (r *gormCreditRepository) Create(ctx context.Context, credit *models.Credit) error {
+	return Conn(ctx, r.db).Omit("Movie", "Person").Create(credit).Error
+}
+
+func (r *gormCreditRepository) Delete(ctx context.Context, credit *models.Credit) error {
+	return Conn(ctx, r.db).Delete(credit).Error
+}
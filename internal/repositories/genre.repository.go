@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// GenreRepository stores the genres movies reference.
+type GenreRepository interface {
+	// List returns every genre ordered by name.
+	List(ctx context.Context) ([]models.Genre, error)
+	Get(ctx context.Context, id uint) (*models.Genre, error)
+	GetBySlug(ctx context.Context, slug string) (*models.Genre, error)
+	// FindByRefs returns the genres matching any of the IDs or slugs, in one query.
+	FindByRefs(ctx context.Context, ids []uint, slugs []string) ([]models.Genre, error)
+	// SlugTaken reports whether a genre other than exceptID uses the slug.
+	SlugTaken(ctx context.Context, slug string, exceptID uint) (bool, error)
+	Create(ctx context.Context, genre *models.Genre) error
+	Update(ctx context.Context, genre *models.Genre) error
+	// Delete returns ErrInUse while movies are assigned to the genre.
+	Delete(ctx context.Context, genre *models.Genre) error
+}
+
+type gormGenreRepository struct {
+	db *gorm.DB
+}
+
+// NewGenreRepository returns a GenreRepository backed by the database.
+func NewGenreRepository(db *gorm.DB) GenreRepository {
+	return &gormGenreRepository{db: db}
+}
+
+func (r *gormGenreRepository) List(ctx context.Context) ([]models.Genre, error) {
+	genres := []models.Genre{}
+	if err := Conn(ctx, r.db).Order("name").Find(&genres).Error; err != nil {
+		return nil, err
+	}
+	return genres, nil
+}
+
+func (r *gormGenreRepository) Get(ctx context.Context, id uint) (*models.Genre, error) {
+	genre := new(models.Genre)
+	if err := Conn(ctx, r.db).First(genre, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return genre, nil
+}
+
+func (r *gormGenreRepository) GetBySlug(ctx context.Context, slug string) (*models.Genre, error) {
+	genre := new(models.Genre)
+	if err := Conn(ctx, r.db).Where("slug = ?", slug).First(genre).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return genre, nil
+}
+
+func (r *gormGenreRepository) FindByRefs(ctx context.Context, ids []uint, slugs []string) ([]models.Genre, error) {
+	genres := []models.Genre{}
+	if len(ids) == 0 && len(slugs) == 0 {
+		return genres, nil
+	}
+
+	db := Conn(ctx, r.db)
+	switch {
+	case len(ids) == 0:
+		db = db.Where("slug IN ?", slugs)
+	case len(slugs) == 0:
+		db = db.Where("id IN ?", ids)
+	default:
+		db = db.Where("id IN ? OR slug IN ?", ids, slugs)
+	}
+	if err := db.Find(&genres).Error; err != nil {
+		return nil, err
+	}
+	return genres, nil
+}
+
+func (r *gormGenreRepository) SlugTaken(ctx context.Context, slug string, exceptID uint) (bool, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.Genre{}).Where("slug = ? AND id <> ?", slug, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormGenreRepository) Create(ctx context.Context, genre *models.Genre) error {
+	return Conn(ctx, r.db).Create(genre).Error
+}
+
+func (r *gormGenreRepository) Update(ctx context.Context, genre *models.Genre) error {
+	return Conn(ctx, r.db).Save(genre).Error
+}
+
+func (r *gormGenreRepository) Delete(ctx context.Context, genre *models.Genre) error {
+	db := Conn(ctx, r.db)
+	return inUse(db, db.Delete(genre).Error)
+}
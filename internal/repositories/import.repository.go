@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// ImportJobRepository stores the bulk movie imports and the report of their rows.
+type ImportJobRepository interface {
+	Get(ctx context.Context, id uint) (*models.ImportJob, error)
+	Create(ctx context.Context, job *models.ImportJob) error
+	// SaveProgress stores the counters of a running import, without its report.
+	SaveProgress(ctx context.Context, job *models.ImportJob) error
+	// Save stores the whole import, with its report.
+	Save(ctx context.Context, job *models.ImportJob) error
+}
+
+type gormImportJobRepository struct {
+	db *gorm.DB
+}
+
+// NewImportJobRepository returns an ImportJobRepository backed by the database.
+func NewImportJobRepository(db *gorm.DB) ImportJobRepository {
+	return &gormImportJobRepository{db: db}
+}
+
+func (r *gormImportJobRepository) Get(ctx context.Context, id uint) (*models.ImportJob, error) {
+	job := new(models.ImportJob)
+	if err := Conn(ctx, r.db).First(job, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return job, nil
+}
+
+func (r *gormImportJobRepository) Create(ctx context.Context, job *models.ImportJob) error {
+	return Conn(ctx, r.db).Create(job).Error
+}
+
+func (r *gormImportJobRepository) SaveProgress(ctx context.Context, job *models.ImportJob) error {
+	return Conn(ctx, r.db).Model(job).Select("processed", "created", "updated", "failed").Updates(job).Error
+}
+
+func (r *gormImportJobRepository) Save(ctx context.Context, job *models.ImportJob) error {
+	return Conn(ctx, r.db).Save(job).Error
+}
@@ -0,0 +1,698 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/cursor"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// The in-memory repositories stand in for the database in handler tests.
+// They follow the semantics of the database repositories, without the
+// director credits, and their transactions do not roll back.
+var (
+	_ Transactor          = MemoryTransactor{}
+	_ MovieRepository     = (*MemoryMovieRepository)(nil)
+	_ GenreRepository     = (*MemoryGenreRepository)(nil)
+	_ RevisionRepository  = (*MemoryRevisionRepository)(nil)
+	_ ImportJobRepository = (*MemoryImportJobRepository)(nil)
+)
+
+// MemoryTransactor runs functions directly, for the in-memory repositories.
+type MemoryTransactor struct{}
+
+func (MemoryTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// MemoryMovieRepository is a MovieRepository keeping movies in memory.
+type MemoryMovieRepository struct {
+	mu     sync.Mutex
+	movies map[uint]models.Movie
+	lastID uint
+}
+
+// NewMemoryMovieRepository returns a MemoryMovieRepository holding the given
+// movies. Movies without an ID get the next free one.
+func NewMemoryMovieRepository(movies ...models.Movie) *MemoryMovieRepository {
+	r := &MemoryMovieRepository{movies: make(map[uint]models.Movie)}
+	for _, movie := range movies {
+		if movie.ID == 0 {
+			movie.ID = r.lastID + 1
+		}
+		if movie.Version == 0 {
+			movie.Version = 1
+		}
+		r.lastID = max(r.lastID, movie.ID)
+		r.movies[movie.ID] = cloneMovie(movie)
+	}
+	return r
+}
+
+func (r *MemoryMovieRepository) List(ctx context.Context, query *queries.MovieListQuery) ([]models.Movie, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	movies := r.filter(func(movie *models.Movie) bool {
+		return !movie.DeletedAt.Valid && matchesMovieFilter(movie, &query.MovieFilterQuery)
+	})
+	column, desc := query.SortColumn(), query.Descending()
+	slices.SortFunc(movies, func(a, b models.Movie) int {
+		return compareMovies(&a, &b, column, desc)
+	})
+	return paginate(movies, &query.PageQuery), int64(len(movies)), nil
+}
+
+func (r *MemoryMovieRepository) ListByCursor(ctx context.Context, query *queries.MovieListQuery, position *cursor.Cursor) ([]models.Movie, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	column, desc := query.SortColumn(), query.Descending()
+	if position != nil && position.Backward {
+		desc = !desc
+	}
+
+	var pivot *models.Movie
+	if position != nil {
+		movie, err := cursorMovie(column, position)
+		if err != nil {
+			return nil, err
+		}
+		pivot = &movie
+	}
+
+	movies := r.filter(func(movie *models.Movie) bool {
+		return !movie.DeletedAt.Valid && matchesMovieFilter(movie, &query.MovieFilterQuery) &&
+			(pivot == nil || compareMovies(movie, pivot, column, desc) > 0)
+	})
+	slices.SortFunc(movies, func(a, b models.Movie) int {
+		return compareMovies(&a, &b, column, desc)
+	})
+	return movies[:min(len(movies), query.Size()+1)], nil
+}
+
+func (r *MemoryMovieRepository) ListTrashed(ctx context.Context, page *queries.PageQuery) ([]models.Movie, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	movies := r.filter(func(movie *models.Movie) bool {
+		return movie.DeletedAt.Valid
+	})
+	slices.SortFunc(movies, func(a, b models.Movie) int {
+		return cmp.Or(b.DeletedAt.Time.Compare(a.DeletedAt.Time), cmp.Compare(a.ID, b.ID))
+	})
+	return paginate(movies, page), int64(len(movies)), nil
+}
+
+func (r *MemoryMovieRepository) ListExpired(ctx context.Context, cutoff time.Time, limit int) ([]models.Movie, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	movies := r.filter(func(movie *models.Movie) bool {
+		return movie.DeletedAt.Valid && movie.DeletedAt.Time.Before(cutoff)
+	})
+	slices.SortFunc(movies, func(a, b models.Movie) int {
+		return cmp.Or(a.DeletedAt.Time.Compare(b.DeletedAt.Time), cmp.Compare(a.ID, b.ID))
+	})
+	return movies[:min(len(movies), limit)], nil
+}
+
+func (r *MemoryMovieRepository) Count(ctx context.Context, opts ...Option) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	options := newOptions(opts)
+	movies := r.filter(func(movie *models.Movie) bool { return visible(movie, options) })
+	return int64(len(movies)), nil
+}
+
+func (r *MemoryMovieRepository) CountByGenre(ctx context.Context, genreID uint) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var count int64
+	for _, movie := range r.movies {
+		if slices.ContainsFunc(movie.Genres, func(genre models.Genre) bool { return genre.ID == genreID }) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MemoryMovieRepository) Get(ctx context.Context, id uint, opts ...Option) (*models.Movie, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	movie, ok := r.movies[id]
+	if !ok || !visible(&movie, newOptions(opts)) {
+		return nil, ErrNotFound
+	}
+
+	movie = cloneMovie(movie)
+	return &movie, nil
+}
+
+func (r *MemoryMovieRepository) GetByTitle(ctx context.Context, title, releaseDate string, opts ...Option) (*models.Movie, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	options := newOptions(opts)
+	var found *models.Movie
+	for _, movie := range r.movies {
+		if movie.Title == title && dateOnly(movie.ReleaseDate) == releaseDate && visible(&movie, options) && (found == nil || movie.ID < found.ID) {
+			clone := cloneMovie(movie)
+			found = &clone
+		}
+	}
+	if found == nil {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
+
+func (r *MemoryMovieRepository) Create(ctx context.Context, movie *models.Movie) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	movie.ID = r.lastID
+	movie.Version = 1
+	movie.DeletedAt = gorm.DeletedAt{}
+	movie.CreatedAt = time.Now()
+	movie.UpdatedAt = movie.CreatedAt
+	r.movies[movie.ID] = cloneMovie(*movie)
+	return nil
+}
+
+func (r *MemoryMovieRepository) Update(ctx context.Context, movie *models.Movie) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.movies[movie.ID]
+	if !ok || stored.DeletedAt.Valid || stored.Version != movie.Version {
+		return ErrVersionConflict
+	}
+
+	movie.Version++
+	movie.CreatedAt = stored.CreatedAt
+	movie.UpdatedAt = time.Now()
+	r.movies[movie.ID] = cloneMovie(*movie)
+	return nil
+}
+
+func (r *MemoryMovieRepository) Delete(ctx context.Context, movie *models.Movie, opts ...Option) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	options := newOptions(opts)
+	stored, ok := r.movies[movie.ID]
+	if !ok || (stored.DeletedAt.Valid && !options.Permanent) || stored.Version != movie.Version {
+		return ErrVersionConflict
+	}
+
+	movie.Version++
+	if options.Permanent {
+		delete(r.movies, movie.ID)
+		return nil
+	}
+	movie.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.movies[movie.ID] = cloneMovie(*movie)
+	return nil
+}
+
+func (r *MemoryMovieRepository) Restore(ctx context.Context, movie *models.Movie) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.movies[movie.ID]
+	if !ok || stored.Version != movie.Version {
+		return ErrVersionConflict
+	}
+
+	stored.Version++
+	stored.DeletedAt = gorm.DeletedAt{}
+	stored.UpdatedAt = time.Now()
+	r.movies[movie.ID] = stored
+	*movie = cloneMovie(stored)
+	return nil
+}
+
+func (r *MemoryMovieRepository) Export(ctx context.Context, filter *queries.MovieFilterQuery, columns []string) (MovieCursor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	movies := r.filter(func(movie *models.Movie) bool {
+		return !movie.DeletedAt.Valid && matchesMovieFilter(movie, filter)
+	})
+	slices.SortFunc(movies, func(a, b models.Movie) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return &memoryMovieCursor{movies: movies, current: -1}, nil
+}
+
+func (r *MemoryMovieRepository) Search(ctx context.Context, query *queries.MovieSearchQuery) ([]models.MovieSearchResult, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// movies are searched like databases without full-text search do
+	clauses := searchClauses(query.Q)
+	words := searchWords(clauses)
+	results := []models.MovieSearchResult{}
+	for _, movie := range r.movies {
+		if movie.DeletedAt.Valid || !matchesSearchClauses(&movie, clauses) {
+			continue
+		}
+
+		result := models.MovieSearchResult{
+			Movie:                cloneMovie(movie),
+			TitleHighlight:       highlightTerms(movie.Title, words),
+			DescriptionHighlight: highlightTerms(movie.Description, words),
+		}
+		for _, term := range words {
+			for _, weighted := range searchWeights {
+				if containsFold(searchColumn(&movie, weighted.column), term) {
+					result.Rank += weighted.weight
+				}
+			}
+		}
+		results = append(results, result)
+	}
+	slices.SortFunc(results, func(a, b models.MovieSearchResult) int {
+		return cmp.Or(cmp.Compare(b.Rank, a.Rank), cmp.Compare(a.ID, b.ID))
+	})
+	return paginate(results, &query.PageQuery), int64(len(results)), nil
+}
+
+func (r *MemoryMovieRepository) Autocomplete(ctx context.Context, query *queries.MovieAutocompleteQuery) ([]models.MovieSuggestion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// every movie outside the trash is a candidate of the trigram ranking
+	q := strings.TrimSpace(query.Q)
+	var candidates []suggestionCandidate
+	for _, movie := range r.filter(func(movie *models.Movie) bool { return !movie.DeletedAt.Valid }) {
+		candidates = append(candidates, suggestionCandidate{
+			MovieSuggestion: movieSuggestion(&movie),
+			Director:        movie.Director,
+			Contained:       containsFold(movie.Title, q) || containsFold(movie.Director, q),
+		})
+	}
+	return rankSuggestions(q, candidates, query.Size()), nil
+}
+
+// memoryMovieCursor iterates over a snapshot of the movies, with every column.
+type memoryMovieCursor struct {
+	movies  []models.Movie
+	current int
+}
+
+func (c *memoryMovieCursor) Next() bool {
+	c.current++
+	return c.current < len(c.movies)
+}
+
+func (c *memoryMovieCursor) Movie() (*models.Movie, error) {
+	return &c.movies[c.current], nil
+}
+
+func (c *memoryMovieCursor) Err() error {
+	return nil
+}
+
+func (c *memoryMovieCursor) Close() error {
+	return nil
+}
+
+// visible reports whether the options reach the movie, like the scopes of the database.
+func visible(movie *models.Movie, options Options) bool {
+	switch {
+	case options.OnlyTrashed:
+		return movie.DeletedAt.Valid
+	case options.WithTrashed || options.Permanent:
+		return true
+	default:
+		return !movie.DeletedAt.Valid
+	}
+}
+
+// filter returns copies of the movies the function keeps.
+func (r *MemoryMovieRepository) filter(keep func(movie *models.Movie) bool) []models.Movie {
+	movies := []models.Movie{}
+	for _, movie := range r.movies {
+		if keep(&movie) {
+			movies = append(movies, cloneMovie(movie))
+		}
+	}
+	return movies
+}
+
+// MemoryGenreRepository is a GenreRepository keeping genres in memory.
+type MemoryGenreRepository struct {
+	mu     sync.Mutex
+	genres []models.Genre
+	lastID uint
+}
+
+// NewMemoryGenreRepository returns a MemoryGenreRepository holding the given
+// genres. Genres without an ID are numbered from one.
+func NewMemoryGenreRepository(genres ...models.Genre) *MemoryGenreRepository {
+	r := &MemoryGenreRepository{genres: slices.Clone(genres)}
+	for i := range r.genres {
+		if r.genres[i].ID == 0 {
+			r.genres[i].ID = uint(i + 1)
+		}
+		r.lastID = max(r.lastID, r.genres[i].ID)
+	}
+	return r
+}
+
+func (r *MemoryGenreRepository) List(ctx context.Context) ([]models.Genre, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	genres := slices.Clone(r.genres)
+	slices.SortFunc(genres, func(a, b models.Genre) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return genres, nil
+}
+
+func (r *MemoryGenreRepository) Get(ctx context.Context, id uint) (*models.Genre, error) {
+	return r.find(func(genre *models.Genre) bool { return genre.ID == id })
+}
+
+func (r *MemoryGenreRepository) GetBySlug(ctx context.Context, slug string) (*models.Genre, error) {
+	return r.find(func(genre *models.Genre) bool { return genre.Slug == slug })
+}
+
+func (r *MemoryGenreRepository) FindByRefs(ctx context.Context, ids []uint, slugs []string) ([]models.Genre, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	genres := []models.Genre{}
+	for _, genre := range r.genres {
+		if slices.Contains(ids, genre.ID) || slices.Contains(slugs, genre.Slug) {
+			genres = append(genres, genre)
+		}
+	}
+	return genres, nil
+}
+
+func (r *MemoryGenreRepository) SlugTaken(ctx context.Context, slug string, exceptID uint) (bool, error) {
+	_, err := r.find(func(genre *models.Genre) bool { return genre.Slug == slug && genre.ID != exceptID })
+	return err == nil, nil
+}
+
+func (r *MemoryGenreRepository) Create(ctx context.Context, genre *models.Genre) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	genre.ID = r.lastID
+	genre.CreatedAt = time.Now()
+	genre.UpdatedAt = genre.CreatedAt
+	r.genres = append(r.genres, *genre)
+	return nil
+}
+
+func (r *MemoryGenreRepository) Update(ctx context.Context, genre *models.Genre) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.IndexFunc(r.genres, func(stored models.Genre) bool { return stored.ID == genre.ID })
+	if i < 0 {
+		return ErrNotFound
+	}
+	genre.UpdatedAt = time.Now()
+	r.genres[i] = *genre
+	return nil
+}
+
+func (r *MemoryGenreRepository) Delete(ctx context.Context, genre *models.Genre) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.genres = slices.DeleteFunc(r.genres, func(stored models.Genre) bool { return stored.ID == genre.ID })
+	return nil
+}
+
+func (r *MemoryGenreRepository) find(match func(genre *models.Genre) bool) (*models.Genre, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, genre := range r.genres {
+		if match(&genre) {
+			return &genre, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// MemoryRevisionRepository is a RevisionRepository keeping revisions in memory.
+type MemoryRevisionRepository struct {
+	mu        sync.Mutex
+	revisions []models.MovieRevision
+}
+
+// NewMemoryRevisionRepository returns an empty MemoryRevisionRepository.
+func NewMemoryRevisionRepository() *MemoryRevisionRepository {
+	return &MemoryRevisionRepository{}
+}
+
+func (r *MemoryRevisionRepository) List(ctx context.Context, movieID uint, page *queries.PageQuery) ([]models.MovieRevision, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revisions := []models.MovieRevision{}
+	for _, revision := range r.revisions {
+		if revision.MovieID == movieID {
+			revisions = append(revisions, revision)
+		}
+	}
+	slices.SortFunc(revisions, func(a, b models.MovieRevision) int {
+		return cmp.Compare(b.Revision, a.Revision)
+	})
+	return paginate(revisions, page), int64(len(revisions)), nil
+}
+
+func (r *MemoryRevisionRepository) Get(ctx context.Context, movieID, revision uint) (*models.MovieRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, found := range r.revisions {
+		if found.MovieID == movieID && found.Revision == revision {
+			return &found, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryRevisionRepository) Create(ctx context.Context, revision *models.MovieRevision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revision.ID = uint(len(r.revisions) + 1)
+	revision.CreatedAt = time.Now()
+	r.revisions = append(r.revisions, *revision)
+	return nil
+}
+
+// MemoryImportJobRepository is an ImportJobRepository keeping imports in memory.
+type MemoryImportJobRepository struct {
+	mu   sync.Mutex
+	jobs map[uint]models.ImportJob
+}
+
+// NewMemoryImportJobRepository returns an empty MemoryImportJobRepository.
+func NewMemoryImportJobRepository() *MemoryImportJobRepository {
+	return &MemoryImportJobRepository{jobs: make(map[uint]models.ImportJob)}
+}
+
+func (r *MemoryImportJobRepository) Get(ctx context.Context, id uint) (*models.ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	job.Report = slices.Clone(job.Report)
+	return &job, nil
+}
+
+func (r *MemoryImportJobRepository) Create(ctx context.Context, job *models.ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job.ID = uint(len(r.jobs) + 1)
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+	r.store(job)
+	return nil
+}
+
+func (r *MemoryImportJobRepository) SaveProgress(ctx context.Context, job *models.ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.jobs[job.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.Processed, stored.Created, stored.Updated, stored.Failed = job.Processed, job.Created, job.Updated, job.Failed
+	stored.UpdatedAt = time.Now()
+	r.jobs[job.ID] = stored
+	return nil
+}
+
+func (r *MemoryImportJobRepository) Save(ctx context.Context, job *models.ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job.UpdatedAt = time.Now()
+	r.store(job)
+	return nil
+}
+
+// store keeps a copy of the job, whose report the caller goes on appending to.
+func (r *MemoryImportJobRepository) store(job *models.ImportJob) {
+	stored := *job
+	stored.Report = slices.Clone(job.Report)
+	r.jobs[job.ID] = stored
+}
+
+// matchesMovieFilter applies queries.MovieFilterQuery.Filter to a movie.
+func matchesMovieFilter(movie *models.Movie, query *queries.MovieFilterQuery) bool {
+	releaseDate := dateOnly(movie.ReleaseDate)
+	switch {
+	case query.Director != "" && !strings.Contains(strings.ToLower(movie.Director), strings.ToLower(strings.TrimSpace(query.Director))):
+		return false
+	case query.Genre != "" && !slices.ContainsFunc(movie.Genres, func(genre models.Genre) bool { return genre.Slug == utils.Slugify(query.Genre) }):
+		return false
+	case query.YearFrom > 0 && releaseDate < fmt.Sprintf("%04d-01-01", query.YearFrom):
+		return false
+	case query.YearTo > 0 && releaseDate > fmt.Sprintf("%04d-12-31", query.YearTo):
+		return false
+	case query.RatingMin > 0 && movie.Rating < query.RatingMin:
+		return false
+	case query.RatingMax > 0 && movie.Rating > query.RatingMax:
+		return false
+	case query.DurationMin > 0 && movie.DurationMinutes < query.DurationMin:
+		return false
+	case query.DurationMax > 0 && movie.DurationMinutes > query.DurationMax:
+		return false
+	}
+	return true
+}
+
+// matchesSearchClauses reports whether the title, director or description of
+// the movie hold the included terms and none of the excluded terms of one of
+// the clauses.
+func matchesSearchClauses(movie *models.Movie, clauses []searchClause) bool {
+	found := func(term string) bool {
+		return slices.ContainsFunc(searchWeights, func(weighted searchWeight) bool {
+			return containsFold(searchColumn(movie, weighted.column), term)
+		})
+	}
+	return slices.ContainsFunc(clauses, func(alternative searchClause) bool {
+		return !slices.ContainsFunc(alternative.include, func(term string) bool { return !found(term) }) &&
+			!slices.ContainsFunc(alternative.exclude, found)
+	})
+}
+
+// searchColumn returns the value of a searched column of the movie.
+func searchColumn(movie *models.Movie, column string) string {
+	switch column {
+	case "title":
+		return movie.Title
+	case "director":
+		return movie.Director
+	default:
+		return movie.Description
+	}
+}
+
+// movieSuggestion returns the title suggestion of a movie.
+func movieSuggestion(movie *models.Movie) models.MovieSuggestion {
+	year, _ := strconv.Atoi(strings.SplitN(movie.ReleaseDate, "-", 2)[0])
+	return models.MovieSuggestion{ID: movie.ID, Title: movie.Title, ReleaseYear: year, PosterURL: movie.PosterURL}
+}
+
+// containsFold reports whether substr is within s, ignoring case.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// compareMovies orders movies by the sort column, using the ID as tie-breaker.
+func compareMovies(a, b *models.Movie, column string, desc bool) int {
+	var order int
+	switch column {
+	case "title":
+		order = strings.Compare(a.Title, b.Title)
+	case "release_date":
+		order = strings.Compare(dateOnly(a.ReleaseDate), dateOnly(b.ReleaseDate))
+	case "rating":
+		order = cmp.Compare(a.Rating, b.Rating)
+	case "duration_minutes":
+		order = cmp.Compare(a.DurationMinutes, b.DurationMinutes)
+	default:
+		order = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	order = cmp.Or(order, cmp.Compare(a.ID, b.ID))
+	if desc {
+		return -order
+	}
+	return order
+}
+
+// cursorMovie returns a movie at the cursor position, to compare movies against.
+func cursorMovie(column string, c *cursor.Cursor) (models.Movie, error) {
+	movie := models.Movie{ID: c.ID}
+	var err error
+	switch column {
+	case "title":
+		movie.Title = c.Value
+	case "release_date":
+		movie.ReleaseDate = c.Value
+	case "rating":
+		movie.Rating, err = strconv.ParseFloat(c.Value, 64)
+	case "duration_minutes":
+		movie.DurationMinutes, err = strconv.Atoi(c.Value)
+	default:
+		movie.CreatedAt, err = time.Parse(time.RFC3339Nano, c.Value)
+	}
+	if err != nil {
+		return movie, cursor.ErrInvalidCursor
+	}
+	return movie, nil
+}
+
+// dateOnly drops the time the database may add to a release date.
+func dateOnly(date string) string {
+	if len(date) > len(time.DateOnly) {
+		return date[:len(time.DateOnly)]
+	}
+	return date
+}
+
+func cloneMovie(movie models.Movie) models.Movie {
+	movie.Genres = slices.Clone(movie.Genres)
+	return movie
+}
+
+// paginate returns the page of items the query asks for.
+func paginate[T any](items []T, page *queries.PageQuery) []T {
+	start := min(page.Skip(), len(items))
+	end := min(start+page.Size(), len(items))
+	return items[start:end]
+}
@@ -0,0 +1,378 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/config/database"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/cursor"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MovieRepository stores movies with their genres. Writes check and bump the
+// movie version, so a movie changed since it was loaded fails with
+// ErrVersionConflict.
+type MovieRepository interface {
+	// List returns a page of the movies matching the query and their total.
+	List(ctx context.Context, query *queries.MovieListQuery) ([]models.Movie, int64, error)
+	// ListByCursor returns the movies after the cursor position, in the
+	// direction of travel, with one extra row telling whether another page exists.
+	ListByCursor(ctx context.Context, query *queries.MovieListQuery, position *cursor.Cursor) ([]models.Movie, error)
+	// ListTrashed returns a page of the movies in the trash, most recently
+	// deleted first, and their total.
+	ListTrashed(ctx context.Context, page *queries.PageQuery) ([]models.Movie, int64, error)
+	// ListExpired returns up to limit movies moved to the trash before the
+	// cutoff, the longest trashed first, with their genres.
+	ListExpired(ctx context.Context, cutoff time.Time, limit int) ([]models.Movie, error)
+	// Count returns the number of movies the options reach, outside the trash by default.
+	Count(ctx context.Context, opts ...Option) (int64, error)
+	// CountByGenre returns the number of movies assigned the genre, trashed ones included.
+	CountByGenre(ctx context.Context, genreID uint) (int64, error)
+	Get(ctx context.Context, id uint, opts ...Option) (*models.Movie, error)
+	// GetByTitle returns the movie with the title and release date, its
+	// natural key, the oldest one when several share it.
+	GetByTitle(ctx context.Context, title, releaseDate string, opts ...Option) (*models.Movie, error)
+	Create(ctx context.Context, movie *models.Movie) error
+	Update(ctx context.Context, movie *models.Movie) error
+	Delete(ctx context.Context, movie *models.Movie, opts ...Option) error
+	Restore(ctx context.Context, movie *models.Movie) error
+	// Search returns a page of the movies outside the trash matching the web
+	// search query, ranked by relevance with the matched words marked, and
+	// their total.
+	Search(ctx context.Context, query *queries.MovieSearchQuery) ([]models.MovieSearchResult, int64, error)
+	// Autocomplete returns the movies whose title or director is most similar
+	// to the input, typos included.
+	Autocomplete(ctx context.Context, query *queries.MovieAutocompleteQuery) ([]models.MovieSuggestion, error)
+	// Export opens a cursor over the movies matching the filters, ordered by
+	// ID, holding the given columns. It reads a consistent snapshot without
+	// the statement timeout, on a connection of its own until it is closed.
+	Export(ctx context.Context, filter *queries.MovieFilterQuery, columns []string) (MovieCursor, error)
+}
+
+// MovieCursor iterates over the movies of an export. It must be closed.
+type MovieCursor interface {
+	Next() bool
+	// Movie returns the current movie, holding the exported columns and the
+	// slugs of its genres.
+	Movie() (*models.Movie, error)
+	Err() error
+	Close() error
+}
+
+type gormMovieRepository struct {
+	db *gorm.DB
+}
+
+// NewMovieRepository returns a MovieRepository backed by the database.
+func NewMovieRepository(db *gorm.DB) MovieRepository {
+	return &gormMovieRepository{db: db}
+}
+
+func (r *gormMovieRepository) List(ctx context.Context, query *queries.MovieListQuery) ([]models.Movie, int64, error) {
+	db := Conn(ctx, r.db)
+
+	var total int64
+	if err := db.Model(&models.Movie{}).Scopes(query.Filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var movies []models.Movie
+	if err := db.Preload("Genres").Scopes(query.Filter, query.SortBy, query.Paginate).Find(&movies).Error; err != nil {
+		return nil, 0, err
+	}
+	return movies, total, nil
+}
+
+func (r *gormMovieRepository) ListByCursor(ctx context.Context, query *queries.MovieListQuery, position *cursor.Cursor) ([]models.Movie, error) {
+	var movies []models.Movie
+	err := Conn(ctx, r.db).Preload("Genres").Scopes(query.Filter, query.Keyset(position)).Find(&movies).Error
+	return movies, err
+}
+
+func (r *gormMovieRepository) ListTrashed(ctx context.Context, page *queries.PageQuery) ([]models.Movie, int64, error) {
+	trashed := Conn(ctx, r.db).Unscoped().Model(&models.Movie{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := trashed.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	movies := []models.Movie{}
+	if err := trashed.Session(&gorm.Session{}).Preload("Genres").Scopes(page.Paginate).Order("deleted_at DESC").Order("id").Find(&movies).Error; err != nil {
+		return nil, 0, err
+	}
+	return movies, total, nil
+}
+
+func (r *gormMovieRepository) ListExpired(ctx context.Context, cutoff time.Time, limit int) ([]models.Movie, error) {
+	movies := []models.Movie{}
+	err := Conn(ctx, r.db).Unscoped().Preload("Genres").
+		Where("deleted_at < ?", cutoff).
+		Order("deleted_at").Order("id").
+		Limit(limit).
+		Find(&movies).Error
+	return movies, err
+}
+
+func (r *gormMovieRepository) Count(ctx context.Context, opts ...Option) (int64, error) {
+	var count int64
+	err := r.scoped(Conn(ctx, r.db), newOptions(opts)).Model(&models.Movie{}).Count(&count).Error
+	return count, err
+}
+
+func (r *gormMovieRepository) CountByGenre(ctx context.Context, genreID uint) (int64, error) {
+	var count int64
+	err := Conn(ctx, r.db).Table("movie_genres").Where("genre_id = ?", genreID).Count(&count).Error
+	return count, err
+}
+
+func (r *gormMovieRepository) Get(ctx context.Context, id uint, opts ...Option) (*models.Movie, error) {
+	db := r.scoped(Conn(ctx, r.db), newOptions(opts))
+
+	movie := new(models.Movie)
+	if err := db.Preload("Genres").First(movie, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return movie, nil
+}
+
+func (r *gormMovieRepository) GetByTitle(ctx context.Context, title, releaseDate string, opts ...Option) (*models.Movie, error) {
+	db := r.scoped(Conn(ctx, r.db), newOptions(opts))
+
+	movie := new(models.Movie)
+	if err := db.Preload("Genres").Where("title = ? AND release_date = ?", title, releaseDate).Order("id").First(movie).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return movie, nil
+}
+
+func (r *gormMovieRepository) Create(ctx context.Context, movie *models.Movie) error {
+	// new movies always start at the first version, outside the trash
+	movie.Version = 1
+	movie.DeletedAt = gorm.DeletedAt{}
+
+	return Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Genres.*").Create(movie).Error; err != nil {
+			return err
+		}
+		return syncDirectorCredit(tx, movie, "")
+	})
+}
+
+func (r *gormMovieRepository) Update(ctx context.Context, movie *models.Movie) error {
+	return Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := bumpMovieVersion(tx, movie); err != nil {
+			return err
+		}
+
+		// the row is locked and still at the loaded version, it holds the previous director
+		var previousDirector string
+		if err := tx.Model(&models.Movie{}).Select("director").Where("id = ?", movie.ID).Scan(&previousDirector).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Omit(clause.Associations).Save(movie).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(movie).Association("Genres").Replace(movie.Genres); err != nil {
+			return err
+		}
+		return syncDirectorCredit(tx, movie, previousDirector)
+	})
+}
+
+func (r *gormMovieRepository) Delete(ctx context.Context, movie *models.Movie, opts ...Option) error {
+	options := newOptions(opts)
+
+	// permanent deletes also remove the genre links and the credits cascade,
+	// the history is kept either way
+	return Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if options.Permanent {
+			if err := bumpMovieVersion(tx.Unscoped(), movie); err != nil {
+				return err
+			}
+			return tx.Unscoped().Select("Genres").Delete(movie).Error
+		}
+		if err := bumpMovieVersion(tx, movie); err != nil {
+			return err
+		}
+		return tx.Delete(movie).Error
+	})
+}
+
+func (r *gormMovieRepository) Restore(ctx context.Context, movie *models.Movie) error {
+	// the genre links and credits were kept in the trash
+	return Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := bumpMovieVersion(tx.Unscoped(), movie); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(movie).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Preload("Genres").First(movie, movie.ID).Error
+	})
+}
+
+func (r *gormMovieRepository) Export(ctx context.Context, filter *queries.MovieFilterQuery, columns []string) (MovieCursor, error) {
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if err := database.LiftStatementTimeout(tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	rows, err := tx.Model(&models.Movie{}).
+		Select(exportSelects(tx, columns)).
+		Scopes(filter.Filter).
+		Order("movies.id").
+		Rows()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return &gormMovieCursor{tx: tx, rows: rows}, nil
+}
+
+// exportSelects returns the select expressions of the exported columns.
+func exportSelects(db *gorm.DB, columns []string) []string {
+	selects := make([]string, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "genres":
+			selects[i] = "(SELECT " + database.GroupConcat(db, "genres.slug", "|") + " FROM movie_genres " +
+				"JOIN genres ON genres.id = movie_genres.genre_id WHERE movie_genres.movie_id = movies.id) AS genres"
+		default:
+			selects[i] = "movies." + column
+		}
+	}
+	return selects
+}
+
+// exportRow is a row of the export cursor, holding the genre slugs separated by "|".
+type exportRow struct {
+	ID              uint
+	Title           string
+	Description     string
+	PosterURL       string
+	ReleaseDate     string
+	Rating          float64
+	DurationMinutes int
+	Director        string
+	Genres          string
+	Version         uint
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// gormMovieCursor reads an export in the transaction it holds.
+type gormMovieCursor struct {
+	tx   *gorm.DB
+	rows *sql.Rows
+}
+
+func (c *gormMovieCursor) Next() bool {
+	return c.rows.Next()
+}
+
+func (c *gormMovieCursor) Movie() (*models.Movie, error) {
+	var row exportRow
+	if err := c.tx.ScanRows(c.rows, &row); err != nil {
+		return nil, err
+	}
+
+	movie := &models.Movie{
+		ID:              row.ID,
+		Title:           row.Title,
+		Description:     row.Description,
+		PosterURL:       row.PosterURL,
+		ReleaseDate:     row.ReleaseDate,
+		Rating:          row.Rating,
+		DurationMinutes: row.DurationMinutes,
+		Director:        row.Director,
+		Genres:          []models.Genre{},
+		Version:         row.Version,
+		CreatedAt:       row.CreatedAt,
+		UpdatedAt:       row.UpdatedAt,
+	}
+	if row.Genres != "" {
+		for _, slug := range strings.Split(row.Genres, "|") {
+			movie.Genres = append(movie.Genres, models.Genre{Slug: slug})
+		}
+	}
+	return movie, nil
+}
+
+func (c *gormMovieCursor) Err() error {
+	return c.rows.Err()
+}
+
+func (c *gormMovieCursor) Close() error {
+	err := c.rows.Close()
+	c.tx.Rollback()
+	return err
+}
+
+// scoped applies the trash options to a query.
+func (r *gormMovieRepository) scoped(db *gorm.DB, options Options) *gorm.DB {
+	switch {
+	case options.OnlyTrashed:
+		return db.Unscoped().Where("deleted_at IS NOT NULL")
+	case options.WithTrashed || options.Permanent:
+		return db.Unscoped()
+	default:
+		return db
+	}
+}
+
+// bumpMovieVersion increments the movie version when the stored version is
+// still the loaded one, which also locks the row for the transaction.
+func bumpMovieVersion(tx *gorm.DB, movie *models.Movie) error {
+	result := tx.Model(movie).Where("version = ?", movie.Version).UpdateColumn("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+
+	movie.Version++
+	return nil
+}
+
+// syncDirectorCredit keeps the director credit in line with the Director
+// field of the movie, replacing the credit of the previous director.
+func syncDirectorCredit(tx *gorm.DB, movie *models.Movie, previous string) error {
+	name := strings.TrimSpace(movie.Director)
+	previous = strings.TrimSpace(previous)
+
+	if previous != "" && !strings.EqualFold(previous, name) {
+		err := tx.
+			Where("movie_id = ? AND role = ?", movie.ID, models.RoleDirector).
+			Where("person_id IN (?)", tx.Model(&models.Person{}).Select("id").Where("LOWER(name) = LOWER(?)", previous)).
+			Delete(&models.Credit{}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var person models.Person
+	err := tx.Where("LOWER(name) = LOWER(?)", name).Order("id").First(&person).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		person = models.Person{Name: name}
+		err = tx.Create(&person).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	credit := models.Credit{MovieID: movie.ID, PersonID: person.ID, Role: models.RoleDirector}
+	return tx.Where(&credit).FirstOrCreate(&credit).Error
+}
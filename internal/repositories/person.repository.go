@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"gorm.io/gorm"
+)
+
+// PersonRepository stores the people credited on movies.
+type PersonRepository interface {
+	// List returns a page of the people matching the query, ordered by name,
+	// and their total.
+	List(ctx context.Context, query *queries.PersonListQuery) ([]models.Person, int64, error)
+	Get(ctx context.Context, id uint) (*models.Person, error)
+	Count(ctx context.Context) (int64, error)
+	Create(ctx context.Context, person *models.Person) error
+	Update(ctx context.Context, person *models.Person) error
+	Delete(ctx context.Context, person *models.Person) error
+}
+
+type gormPersonRepository struct {
+	db *gorm.DB
+}
+
+// NewPersonRepository returns a PersonRepository backed by the database.
+func NewPersonRepository(db *gorm.DB) PersonRepository {
+	return &gormPersonRepository{db: db}
+}
+
+func (r *gormPersonRepository) List(ctx context.Context, query *queries.PersonListQuery) ([]models.Person, int64, error) {
+	db := Conn(ctx, r.db)
+
+	var total int64
+	if err := db.Model(&models.Person{}).Scopes(query.Filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	people := []models.Person{}
+	if err := db.Scopes(query.Filter, query.Paginate).Order("name").Order("id").Find(&people).Error; err != nil {
+		return nil, 0, err
+	}
+	return people, total, nil
+}
+
+func (r *gormPersonRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.Person{}).Count(&count).Error
+	return count, err
+}
+
+func (r *gormPersonRepository) Get(ctx context.Context, id uint) (*models.Person, error) {
+	person := new(models.Person)
+	if err := Conn(ctx, r.db).First(person, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return person, nil
+}
+
+func (r *gormPersonRepository) Create(ctx context.Context, person *models.Person) error {
+	return Conn(ctx, r.db).Create(person).Error
+}
+
+func (r *gormPersonRepository) Update(ctx context.Context, person *models.Person) error {
+	return Conn(ctx, r.db).Save(person).Error
+}
+
+func (r *gormPersonRepository) Delete(ctx context.Context, person *models.Person) error {
+	return Conn(ctx, r.db).Delete(person).Error
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/config/database"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound is returned when no record matches.
+	ErrNotFound = errors.New("record not found")
+	// ErrVersionConflict is returned when a movie was changed since it was loaded.
+	ErrVersionConflict = errors.New("the movie was modified since it was loaded")
+	// ErrInUse is returned when a record cannot be deleted while others reference it.
+	ErrInUse = errors.New("the record is still referenced")
+)
+
+// Options narrow down which records a repository call reaches.
+type Options struct {
+	WithTrashed bool
+	OnlyTrashed bool
+	Permanent   bool
+}
+
+// Option sets one of the Options.
+type Option func(*Options)
+
+// WithTrashed includes the records in the trash.
+func WithTrashed() Option {
+	return func(o *Options) { o.WithTrashed = true }
+}
+
+// OnlyTrashed restricts to the records in the trash.
+func OnlyTrashed() Option {
+	return func(o *Options) { o.OnlyTrashed = true }
+}
+
+// Permanently deletes records for good, including from the trash.
+func Permanently() Option {
+	return func(o *Options) { o.Permanent = true }
+}
+
+func newOptions(opts []Option) Options {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// Transactor runs functions in a transaction. Repository calls made with the
+// context passed to fn join the transaction.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type (
+	txKey      struct{}
+	replicaKey struct{}
+)
+
+type gormTransactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor returns a Transactor running database transactions.
+func NewTransactor(db *gorm.DB) Transactor {
+	return &gormTransactor{db: db}
+}
+
+func (t *gormTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return Conn(ctx, t.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// FromReplica returns a context whose repository reads may go to a read
+// replica. They see the writes of the primary late, if a replica lags.
+func FromReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaKey{}, true)
+}
+
+// Conn returns the transaction carried by the context, or db bound to the
+// context, reading from a replica when the context allows it.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	if _, ok := ctx.Value(replicaKey{}).(bool); ok {
+		return database.ReadFromReplica(db.WithContext(ctx))
+	}
+	return db.WithContext(ctx)
+}
+
+// notFound translates the gorm not found error to ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// sqliteConstraintTrigger is the extended code SQLite reports when an ON
+// DELETE RESTRICT action refuses a delete, which its dialector leaves as is.
+const sqliteConstraintTrigger = 1811
+
+// inUse translates the foreign key violations of the database to ErrInUse.
+func inUse(db *gorm.DB, err error) error {
+	if err == nil {
+		return nil
+	}
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		if errors.Is(translator.Translate(err), gorm.ErrForeignKeyViolated) {
+			return ErrInUse
+		}
+	}
+	var coded interface{ Code() int }
+	if db.Dialector.Name() == "sqlite" && errors.As(err, &coded) && coded.Code() == sqliteConstraintTrigger {
+		return ErrInUse
+	}
+	return err
+}
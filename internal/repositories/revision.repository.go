@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"gorm.io/gorm"
+)
+
+// RevisionRepository stores the audit history of movies.
+type RevisionRepository interface {
+	// List returns a page of the revisions of a movie, newest first, and their total.
+	List(ctx context.Context, movieID uint, page *queries.PageQuery) ([]models.MovieRevision, int64, error)
+	Get(ctx context.Context, movieID, revision uint) (*models.MovieRevision, error)
+	Create(ctx context.Context, revision *models.MovieRevision) error
+}
+
+type gormRevisionRepository struct {
+	db *gorm.DB
+}
+
+// NewRevisionRepository returns a RevisionRepository backed by the database.
+func NewRevisionRepository(db *gorm.DB) RevisionRepository {
+	return &gormRevisionRepository{db: db}
+}
+
+func (r *gormRevisionRepository) List(ctx context.Context, movieID uint, page *queries.PageQuery) ([]models.MovieRevision, int64, error) {
+	db := Conn(ctx, r.db)
+
+	var total int64
+	if err := db.Model(&models.MovieRevision{}).Where("movie_id = ?", movieID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	revisions := []models.MovieRevision{}
+	if err := db.Where("movie_id = ?", movieID).Order("revision DESC").Scopes(page.Paginate).Find(&revisions).Error; err != nil {
+		return nil, 0, err
+	}
+	return revisions, total, nil
+}
+
+func (r *gormRevisionRepository) Get(ctx context.Context, movieID, revision uint) (*models.MovieRevision, error) {
+	found := new(models.MovieRevision)
+	if err := Conn(ctx, r.db).Where("movie_id = ? AND revision = ?", movieID, revision).First(found).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return found, nil
+}
+
+func (r *gormRevisionRepository) Create(ctx context.Context, revision *models.MovieRevision) error {
+	return Conn(ctx, r.db).Create(revision).Error
+}
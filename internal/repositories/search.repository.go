@@ -0,0 +1,315 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/config/database"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/trigram"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	titleHeadlineOptions       = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	descriptionHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2"
+)
+
+func (r *gormMovieRepository) Search(ctx context.Context, query *queries.MovieSearchQuery) ([]models.MovieSearchResult, int64, error) {
+	db := Conn(ctx, r.db)
+
+	var total int64
+	if err := matchingMovies(db, query.Q).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var results []models.MovieSearchResult
+	err := rankedMovies(db, query.Q).
+		Order("search_rank DESC").
+		Order("movies.id").
+		Scopes(query.Paginate).
+		Scan(&results).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// mark the matched words where the database cannot
+	if !database.IsPostgres(db) {
+		words := searchWords(searchClauses(query.Q))
+		for i := range results {
+			results[i].TitleHighlight = highlightTerms(results[i].TitleHighlight, words)
+			results[i].DescriptionHighlight = highlightTerms(results[i].DescriptionHighlight, words)
+		}
+	}
+
+	if err := loadResultGenres(db, results); err != nil {
+		return nil, 0, err
+	}
+	return results, total, nil
+}
+
+func (r *gormMovieRepository) Autocomplete(ctx context.Context, query *queries.MovieAutocompleteQuery) ([]models.MovieSuggestion, error) {
+	q := strings.TrimSpace(query.Q)
+	db := Conn(ctx, r.db)
+	if !database.IsPostgres(db) {
+		return autocompleteByTrigrams(db, q, query.Size())
+	}
+
+	// prefix matches keep very short inputs useful
+	suggestions := []models.MovieSuggestion{}
+	err := db.Model(&models.Movie{}).
+		Select("id, title, "+database.Year(db, "release_date")+" AS release_year, poster_url").
+		Where("? <% title OR ? <% director OR title ILIKE ?", q, q, database.EscapeLike(q)+"%").
+		Clauses(clause.OrderBy{
+			Expression: gorm.Expr("GREATEST(word_similarity(?, title), word_similarity(?, director)) DESC, title", q, q),
+		}).
+		Limit(query.Size()).
+		Scan(&suggestions).Error
+	if err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// autocompleteCandidates caps the movies the trigram fallback of the
+// autocomplete ranks, those sharing the most trigrams with the input.
+const autocompleteCandidates = 200
+
+// autocompleteByTrigrams suggests movies on databases without pg_trgm. The
+// movies containing the input or enough of its trigrams are selected and
+// ranked by word similarity like PostgreSQL ranks them.
+func autocompleteByTrigrams(db *gorm.DB, q string, size int) ([]models.MovieSuggestion, error) {
+	pattern := "%" + database.EscapeLike(q) + "%"
+	columns := "id, title, director, " + database.Year(db, "release_date") + " AS release_year, poster_url, " +
+		"CASE WHEN " + database.ILike(db, "title") + " OR " + database.ILike(db, "director") + " THEN 1 ELSE 0 END AS contained"
+	args := []interface{}{pattern, pattern}
+
+	// count the trigrams of the input each column contains
+	trigrams, least := trigram.Required(q, trigram.Threshold)
+	shared := map[string][]string{"title": {"0"}, "director": {"0"}}
+	for _, column := range []string{"title", "director"} {
+		for _, t := range trigrams {
+			shared[column] = append(shared[column], "CASE WHEN "+database.ILike(db, column)+" THEN 1 ELSE 0 END")
+			args = append(args, "%"+t+"%")
+		}
+		columns += ", (" + strings.Join(shared[column], " + ") + ") AS " + column + "_trigrams"
+	}
+
+	condition := "contained = 1"
+	if len(trigrams) > 0 {
+		condition += " OR title_trigrams >= " + strconv.Itoa(least) + " OR director_trigrams >= " + strconv.Itoa(least)
+	}
+	var candidates []suggestionCandidate
+	err := db.Table("(?) AS candidates", db.Model(&models.Movie{}).Select(columns, args...)).
+		Where(condition).
+		Order("contained DESC, title_trigrams + director_trigrams DESC, title").
+		Limit(autocompleteCandidates).
+		Scan(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+	return rankSuggestions(q, candidates, size), nil
+}
+
+// suggestionCandidate is a movie the autocomplete fallbacks rank.
+type suggestionCandidate struct {
+	models.MovieSuggestion
+	Director  string
+	Contained bool
+}
+
+// rankSuggestions keeps the candidates containing the input or similar
+// enough to it by title or director, most similar first, then by title.
+func rankSuggestions(q string, candidates []suggestionCandidate, size int) []models.MovieSuggestion {
+	type ranked struct {
+		suggestion models.MovieSuggestion
+		similarity float64
+	}
+	var matches []ranked
+	for _, candidate := range candidates {
+		similarity := max(trigram.WordSimilarity(q, candidate.Title), trigram.WordSimilarity(q, candidate.Director))
+		if candidate.Contained || similarity >= trigram.Threshold {
+			matches = append(matches, ranked{candidate.MovieSuggestion, similarity})
+		}
+	}
+	slices.SortFunc(matches, func(a, b ranked) int {
+		return cmp.Or(cmp.Compare(b.similarity, a.similarity), strings.Compare(a.suggestion.Title, b.suggestion.Title))
+	})
+
+	suggestions := []models.MovieSuggestion{}
+	for _, match := range matches[:min(len(matches), size)] {
+		suggestions = append(suggestions, match.suggestion)
+	}
+	return suggestions
+}
+
+// loadResultGenres attaches the genres to scanned search results, which
+// cannot use Preload.
+func loadResultGenres(db *gorm.DB, results []models.MovieSearchResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(results))
+	for i := range results {
+		ids[i] = results[i].ID
+	}
+
+	var movies []models.Movie
+	if err := db.Select("id").Preload("Genres").Find(&movies, ids).Error; err != nil {
+		return err
+	}
+
+	genres := make(map[uint][]models.Genre, len(movies))
+	for _, movie := range movies {
+		genres[movie.ID] = movie.Genres
+	}
+	for i := range results {
+		results[i].Genres = genres[results[i].ID]
+	}
+	return nil
+}
+
+// matchingMovies selects the movies outside the trash whose search vector
+// matches the web search query. Other databases than PostgreSQL match every
+// word or quoted phrase of the query, or one of the words joined by OR, as a
+// substring of the title, director or description, and exclude the words
+// prefixed with a minus.
+func matchingMovies(db *gorm.DB, q string) *gorm.DB {
+	if database.IsPostgres(db) {
+		return db.
+			Table("movies, websearch_to_tsquery('english', ?) AS query", q).
+			Where("movies.search_vector @@ query AND movies.deleted_at IS NULL")
+	}
+
+	db = db.Table("movies").Where("movies.deleted_at IS NULL")
+	condition := "(" + database.ILike(db, "movies.title") + " OR " + database.ILike(db, "movies.director") + " OR " + database.ILike(db, "movies.description") + ")"
+	// an empty query matches nothing, like on PostgreSQL
+	alternatives := searchClauses(q)
+	if len(alternatives) == 0 {
+		return db.Where("1 = 0")
+	}
+	conditions := make([]string, len(alternatives))
+	var args []interface{}
+	for i, alternative := range alternatives {
+		var terms []string
+		for _, term := range alternative.include {
+			pattern := "%" + database.EscapeLike(term) + "%"
+			terms = append(terms, condition)
+			args = append(args, pattern, pattern, pattern)
+		}
+		for _, term := range alternative.exclude {
+			pattern := "%" + database.EscapeLike(term) + "%"
+			terms = append(terms, "NOT "+condition)
+			args = append(args, pattern, pattern, pattern)
+		}
+		conditions[i] = "(" + strings.Join(terms, " AND ") + ")"
+	}
+	return db.Where(strings.Join(conditions, " OR "), args...)
+}
+
+// searchWeight is the rank a matched word adds in a column.
+type searchWeight struct {
+	column string
+	weight float64
+}
+
+// searchWeights ranks the columns like the weights of the search vector.
+var searchWeights = []searchWeight{
+	{"title", 1.0},
+	{"director", 0.4},
+	{"description", 0.2},
+}
+
+// rankedMovies selects the matching movies with their rank and highlights.
+// Other databases than PostgreSQL weigh the matched words like the search
+// vector does and leave the highlights to highlightTerms.
+func rankedMovies(db *gorm.DB, q string) *gorm.DB {
+	db = matchingMovies(db, q)
+	if database.IsPostgres(db) {
+		return db.Select("movies.*, ts_rank_cd(movies.search_vector, query) AS search_rank, "+
+			"ts_headline('english', movies.title, query, ?) AS title_highlight, "+
+			"ts_headline('english', movies.description, query, ?) AS description_highlight",
+			titleHeadlineOptions, descriptionHeadlineOptions)
+	}
+
+	rank := []string{"0"}
+	var args []interface{}
+	for _, term := range searchWords(searchClauses(q)) {
+		pattern := "%" + database.EscapeLike(term) + "%"
+		for _, weighted := range searchWeights {
+			weight := strconv.FormatFloat(weighted.weight, 'f', -1, 64)
+			rank = append(rank, "CASE WHEN "+database.ILike(db, "movies."+weighted.column)+" THEN "+weight+" ELSE 0 END")
+			args = append(args, pattern)
+		}
+	}
+	return db.Select("movies.*, ("+strings.Join(rank, " + ")+") AS search_rank, "+
+		"movies.title AS title_highlight, movies.description AS description_highlight", args...)
+}
+
+// searchToken matches a word or a quoted phrase of a web search query, with
+// the minus negating it.
+var searchToken = regexp.MustCompile(`(-?)(?:"([^"]*)"?|(\S+))`)
+
+// searchClause is a part of a web search query between two ORs, matching the
+// movies with every included term and none of the excluded ones.
+type searchClause struct {
+	include []string
+	exclude []string
+}
+
+// searchClauses splits a web search query into its clauses joined by OR, for
+// databases without full-text search. Terms are words or quoted phrases,
+// excluded when prefixed with a minus, and OR binds looser than the terms
+// around it, like in websearch_to_tsquery.
+func searchClauses(q string) []searchClause {
+	clauses := []searchClause{{}}
+	for _, match := range searchToken.FindAllStringSubmatch(q, -1) {
+		negated, phrase := match[1] == "-", match[2] != ""
+		term := strings.ReplaceAll(match[3], `"`, "")
+		if phrase {
+			term = strings.Join(strings.Fields(match[2]), " ")
+		}
+
+		last := &clauses[len(clauses)-1]
+		switch {
+		case term == "" || term == "-":
+		case !phrase && !negated && strings.EqualFold(term, "or"):
+			clauses = append(clauses, searchClause{})
+		case negated:
+			last.exclude = append(last.exclude, term)
+		default:
+			last.include = append(last.include, term)
+		}
+	}
+	return slices.DeleteFunc(clauses, func(alternative searchClause) bool {
+		return len(alternative.include) == 0 && len(alternative.exclude) == 0
+	})
+}
+
+// searchWords returns the included terms of the clauses, which rank and
+// highlight the results.
+func searchWords(clauses []searchClause) []string {
+	var words []string
+	for _, alternative := range clauses {
+		words = append(words, alternative.include...)
+	}
+	return words
+}
+
+// highlightTerms marks the occurrences of the words in text, ignoring case.
+func highlightTerms(text string, words []string) string {
+	patterns := make([]string, len(words))
+	for i, word := range words {
+		patterns[i] = regexp.QuoteMeta(word)
+	}
+	if len(patterns) == 0 {
+		return text
+	}
+	return regexp.MustCompile(`(?i)`+strings.Join(patterns, "|")).ReplaceAllString(text, "<mark>$0</mark>")
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenRepository stores the issued refresh tokens and the revoked access tokens.
+type TokenRepository interface {
+	CreateRefresh(ctx context.Context, token *models.RefreshToken) error
+	GetRefresh(ctx context.Context, jti string) (*models.RefreshToken, error)
+	// RevokeRefresh revokes an active refresh token and reports whether it
+	// was still active, so that a token is only rotated once.
+	RevokeRefresh(ctx context.Context, jti string) (bool, error)
+	// RevokeUserRefresh revokes every active refresh token of the user.
+	RevokeUserRefresh(ctx context.Context, userID uint) error
+	// RevokeAccess adds an access token to the revocation list, once.
+	RevokeAccess(ctx context.Context, token *models.RevokedToken) error
+	// AccessRevoked reports whether the access token is on the revocation list.
+	AccessRevoked(ctx context.Context, jti string) (bool, error)
+	// DeleteExpired deletes the refresh tokens and the revocation list
+	// entries expired before the time, and returns how many it deleted.
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type gormTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenRepository returns a TokenRepository backed by the database.
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &gormTokenRepository{db: db}
+}
+
+func (r *gormTokenRepository) CreateRefresh(ctx context.Context, token *models.RefreshToken) error {
+	return Conn(ctx, r.db).Create(token).Error
+}
+
+func (r *gormTokenRepository) GetRefresh(ctx context.Context, jti string) (*models.RefreshToken, error) {
+	token := new(models.RefreshToken)
+	if err := Conn(ctx, r.db).Where("jti = ?", jti).First(token).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return token, nil
+}
+
+func (r *gormTokenRepository) RevokeRefresh(ctx context.Context, jti string) (bool, error) {
+	result := Conn(ctx, r.db).Model(&models.RefreshToken{}).
+		Where("jti = ? AND revoked_at IS NULL", jti).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *gormTokenRepository) RevokeUserRefresh(ctx context.Context, userID uint) error {
+	return Conn(ctx, r.db).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *gormTokenRepository) RevokeAccess(ctx context.Context, token *models.RevokedToken) error {
+	return Conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func (r *gormTokenRepository) AccessRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	db := Conn(ctx, r.db)
+
+	refresh := db.Where("expires_at < ?", before).Delete(&models.RefreshToken{})
+	if refresh.Error != nil {
+		return 0, refresh.Error
+	}
+	revoked := db.Where("expires_at < ?", before).Delete(&models.RevokedToken{})
+	return refresh.RowsAffected + revoked.RowsAffected, revoked.Error
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/queries"
+	"gorm.io/gorm"
+)
+
+// UserRepository stores the user accounts. Emails are stored lowercased.
+type UserRepository interface {
+	// List returns a page of the users ordered by ID, and their total.
+	List(ctx context.Context, page *queries.PageQuery) ([]models.User, int64, error)
+	Get(ctx context.Context, id uint) (*models.User, error)
+	// CountByRole returns the number of users of every role that has any.
+	CountByRole(ctx context.Context) (map[string]int64, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	EmailTaken(ctx context.Context, email string) (bool, error)
+	Create(ctx context.Context, user *models.User) error
+	UpdateRole(ctx context.Context, user *models.User, role string) error
+}
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository returns a UserRepository backed by the database.
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &gormUserRepository{db: db}
+}
+
+func (r *gormUserRepository) List(ctx context.Context, page *queries.PageQuery) ([]models.User, int64, error) {
+	db := Conn(ctx, r.db)
+
+	var total int64
+	if err := db.Model(&models.User{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	users := []models.User{}
+	if err := db.Scopes(page.Paginate).Order("id").Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *gormUserRepository) Get(ctx context.Context, id uint) (*models.User, error) {
+	user := new(models.User)
+	if err := Conn(ctx, r.db).First(user, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return user, nil
+}
+
+func (r *gormUserRepository) CountByRole(ctx context.Context) (map[string]int64, error) {
+	var roles []struct {
+		Role  string
+		Count int64
+	}
+	if err := Conn(ctx, r.db).Model(&models.User{}).Select("role, COUNT(*) AS count").Group("role").Scan(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(roles))
+	for _, role := range roles {
+		counts[role.Role] = role.Count
+	}
+	return counts, nil
+}
+
+func (r *gormUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	user := new(models.User)
+	if err := Conn(ctx, r.db).Where("email = ?", email).First(user).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return user, nil
+}
+
+func (r *gormUserRepository) EmailTaken(ctx context.Context, email string) (bool, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.User{}).Where("email = ?", email).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormUserRepository) Create(ctx context.Context, user *models.User) error {
+	return Conn(ctx, r.db).Create(user).Error
+}
+
+func (r *gormUserRepository) UpdateRole(ctx context.Context, user *models.User, role string) error {
+	return Conn(ctx, r.db).Model(user).Update("role", role).Error
+}
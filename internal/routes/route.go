@@ -2,25 +2,123 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/swagger"
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
 	"github.com/zdacoder/go-fiber-movie-app-api/internal/handlers"
 	"github.com/zdacoder/go-fiber-movie-app-api/internal/middlewares"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/metrics"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/ratelimit"
 )
 
-func Init(app *fiber.App) {
+func Init(app *fiber.App, config *config.Config, h *handlers.Handler) {
+	// Metrics middleware, first so that it times the whole request
+	if config.MetricsEnabled {
+		app.Use(middlewares.MetricsMiddleware())
+	}
+
+	// Tracing middleware, before the request ID so that logs carry the trace ID
+	app.Use(middlewares.TracingMiddleware())
+
 	// Apply middlewares
+	app.Use(middlewares.RequestIDMiddleware())
 	app.Use(middlewares.LoggerMiddleware())
 
 	// CORS Middleware
 	app.Use(middlewares.CORSMiddleware())
 
+	// Authentication middleware, anonymous requests stay allowed for reads,
+	// IPs sending too many invalid credentials are refused before the lookup
+	rateLimitStore := middlewares.NewRateLimitStore(config)
+	app.Use(middlewares.AuthFailureLimitMiddleware(config, rateLimitStore))
+	app.Use(middlewares.AuthMiddleware(h.Tokens, h.APIKeys))
+
+	// Rate limiting middlewares, every API request counts against the default
+	// limits and some groups against stricter ones as well
+	app.Use("/api", middlewares.RateLimitMiddleware(config, rateLimitStore, ratelimit.GroupDefault))
+	searchLimit := middlewares.RateLimitMiddleware(config, rateLimitStore, ratelimit.GroupSearch)
+	authLimit := middlewares.RateLimitMiddleware(config, rateLimitStore, ratelimit.GroupAuth)
+
+	// Permission middlewares, writes require an editor, an admin or an API key scoped for them
+	reader := middlewares.RequirePermission(models.PermissionMoviesRead)
+	editor := middlewares.RequirePermission(models.PermissionMoviesWrite)
+	admin := middlewares.RequirePermission(models.PermissionAdmin)
+
+	// Conditional request middleware for movie writes
+	precondition := middlewares.PreconditionMiddleware(config)
+
+	// Imports upload whole catalogs, past the body limit of the other routes
+	app.Server().HeaderReceived = middlewares.RouteBodyLimits(map[string]int{
+		fiber.MethodPost + " /api/movies/import": config.ImportMaxSize,
+	})
+
+	// Auth routes
+	auth := app.Group("/api/auth", authLimit)
+	auth.Post("/register", h.Register)
+	auth.Post("/login", h.Login)
+	auth.Post("/refresh", h.RefreshTokens)
+	auth.Post("/logout", h.Logout)
+
+	// User routes
+	users := app.Group("/api/users", admin)
+	users.Get("/", h.ListUsers)
+	users.Put("/:id/role", h.UpdateUserRole)
+
+	// API key routes
+	apiKeys := app.Group("/api/api-keys", admin)
+	apiKeys.Get("/", h.ListAPIKeys)
+	apiKeys.Post("/", h.CreateAPIKey)
+	apiKeys.Delete("/:id", h.RevokeAPIKey)
+
 	// Movie routes
 	movies := app.Group("/api/movies")
-	movies.Get("/", handlers.ListMovies)
-	movies.Get("/:id", handlers.GetMovie)
-	movies.Post("/", handlers.CreateMovie)
-	movies.Put("/:id", handlers.UpdateMovie)
-	movies.Delete("/:id", handlers.DeleteMovie)
+	movies.Get("/", h.ListMovies)
+	movies.Get("/search", searchLimit, h.SearchMovies)
+	movies.Get("/autocomplete", searchLimit, h.AutocompleteMovies)
+	movies.Get("/export", reader, h.ExportMovies)
+	movies.Get("/trash", editor, h.ListTrashedMovies)
+	movies.Post("/import", editor, h.ImportMovies)
+	movies.Get("/import/:id", editor, h.GetImportJob)
+	movies.Get("/:id", h.GetMovie)
+	movies.Post("/", editor, h.CreateMovie)
+	movies.Put("/:id", editor, precondition, h.UpdateMovie)
+	movies.Patch("/:id", editor, precondition, h.PatchMovie)
+	movies.Delete("/:id", editor, precondition, h.DeleteMovie)
+	movies.Post("/:id/restore", editor, precondition, h.RestoreMovie)
+	movies.Get("/:id/history", reader, h.ListMovieHistory)
+	movies.Get("/:id/history/:rev", reader, h.GetMovieRevision)
+	movies.Post("/:id/revert/:rev", editor, precondition, h.RevertMovie)
+	movies.Get("/:id/credits", h.ListMovieCredits)
+	movies.Post("/:id/credits", editor, h.CreateMovieCredit)
+	movies.Delete("/:id/credits/:creditId", editor, h.DeleteMovieCredit)
+
+	// Genre routes
+	genres := app.Group("/api/genres")
+	genres.Get("/", h.ListGenres)
+	genres.Get("/:slug", h.GetGenre)
+	genres.Get("/:slug/movies", h.ListGenreMovies)
+	genres.Post("/", editor, h.CreateGenre)
+	genres.Put("/:slug", editor, h.UpdateGenre)
+	genres.Delete("/:slug", editor, h.DeleteGenre)
+
+	// People routes
+	people := app.Group("/api/people")
+	people.Get("/", h.ListPeople)
+	people.Get("/:id", h.GetPerson)
+	people.Get("/:id/filmography", h.GetFilmography)
+	people.Post("/", editor, h.CreatePerson)
+	people.Put("/:id", editor, h.UpdatePerson)
+	people.Delete("/:id", editor, h.DeletePerson)
+
+	// Health routes, for liveness and readiness probes
+	app.Get("/healthz", h.Liveness)
+	app.Get("/readyz", h.Readiness)
+
+	// Prometheus metrics route
+	if config.MetricsEnabled {
+		app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
+	}
 
 	// Swagger documentation route
 	app.Get("/swagger/*", swagger.HandlerDefault)
@@ -0,0 +1,100 @@
+package seeds
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"gorm.io/gorm"
+)
+
+var (
+	fakeAdjectives = []string{"Silent", "Crimson", "Last", "Hidden", "Broken", "Golden", "Endless", "Forgotten", "Midnight", "Savage", "Electric", "Quiet", "Burning", "Distant", "Restless"}
+	fakeNouns      = []string{"Harbor", "Kingdom", "Signal", "Orchard", "Frontier", "Mirror", "Empire", "Tide", "Garden", "Machine", "Witness", "Horizon", "Voyage", "Monsoon", "Labyrinth"}
+	fakeHeroes     = []string{"a retired detective", "an estranged sister", "a young engineer", "a small-town doctor", "a disgraced pilot", "an exiled prince", "a street musician", "a lighthouse keeper"}
+	fakePlots      = []string{"uncovers a conspiracy", "must cross a hostile country", "races against time to stop a disaster", "returns home after twenty years", "is drawn into a deadly game", "discovers a family secret", "falls for an unlikely stranger"}
+	fakeSettings   = []string{"in post-war Jakarta", "on a remote island", "in a city that never sleeps", "aboard a failing space station", "across the Sahara", "in a snowed-in mountain village", "in the near future"}
+	fakeFirstNames = []string{"Ava", "Rafael", "Mei", "Tomasz", "Amara", "Kenji", "Lucía", "Dewi", "Oskar", "Nadia", "Elliot", "Priya"}
+	fakeLastNames  = []string{"Hartmann", "Okafor", "Santoso", "Lindqvist", "Moreau", "Tanaka", "Alvarez", "Novak", "Reyes", "Whitfield", "Kowalski", "Haddad"}
+)
+
+// Fake creates n randomized movies, each one valid for the API, together with
+// the curated genres they are picked from. Their directors are created as
+// people with a director credit, unlike seeding it is not idempotent.
+func Fake(ctx context.Context, db *gorm.DB, n int) (*Result, error) {
+	var genres []models.Genre
+	if err := loadFixtures("genres.json", &genres); err != nil {
+		return nil, err
+	}
+
+	result := new(Result)
+	movieRepository := repositories.NewMovieRepository(db)
+	err := repositories.NewTransactor(db).WithinTransaction(ctx, func(ctx context.Context) error {
+		genresBySlug, err := seedGenres(repositories.Conn(ctx, db), genres, result)
+		if err != nil {
+			return err
+		}
+		choices := make([]models.Genre, 0, len(genresBySlug))
+		for _, genre := range genresBySlug {
+			choices = append(choices, genre)
+		}
+
+		for range n {
+			movie := fakeMovie(choices)
+			if errs := validators.ValidateStruct(movie); errs != nil {
+				return fmt.Errorf("fake movie %q: %s", movie.Title, strings.Join(errs, ", "))
+			}
+			if err := movieRepository.Create(ctx, movie); err != nil {
+				return err
+			}
+			result.Movies++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fakeMovie returns a random movie with one to three of the genres.
+func fakeMovie(genres []models.Genre) *models.Movie {
+	title := fmt.Sprintf("The %s %s", pick(fakeAdjectives), pick(fakeNouns))
+	if rand.IntN(3) == 0 {
+		title = fmt.Sprintf("%s of the %s", pick(fakeNouns), pick(fakeNouns))
+	}
+
+	// release dates between 1950 and last year
+	start := time.Date(1950, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(time.Now().Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	releaseDate := start.AddDate(0, 0, rand.IntN(int(end.Sub(start).Hours()/24)))
+
+	hero := pick(fakeHeroes)
+	description := fmt.Sprintf("%s %s %s.", strings.ToUpper(hero[:1])+hero[1:], pick(fakePlots), pick(fakeSettings))
+
+	picked := make([]models.Genre, 0, 3)
+	for _, i := range rand.Perm(len(genres))[:1+rand.IntN(min(3, len(genres)))] {
+		picked = append(picked, genres[i])
+	}
+
+	return &models.Movie{
+		Title:           title,
+		Description:     description,
+		PosterURL:       "https://placehold.co/500x750?text=" + url.QueryEscape(title),
+		ReleaseDate:     releaseDate.Format("2006-01-02"),
+		Rating:          float64(10+rand.IntN(91)) / 10,
+		DurationMinutes: 75 + rand.IntN(106),
+		Director:        pick(fakeFirstNames) + " " + pick(fakeLastNames),
+		Genres:          picked,
+	}
+}
+
+func pick(values []string) string {
+	return values[rand.IntN(len(values))]
+}
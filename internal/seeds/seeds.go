@@ -0,0 +1,197 @@
+package seeds
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"gorm.io/gorm"
+)
+
+//go:embed fixtures/*.json
+var fixtureFiles embed.FS
+
+// Result counts the records a seed created, and the ones it skipped because
+// they were already present.
+type Result struct {
+	Genres  int
+	People  int
+	Movies  int
+	Credits int
+	Skipped int
+}
+
+// movieFixture is a movie with its genres as slugs and its credits by person name.
+type movieFixture struct {
+	models.Movie
+	Credits []creditFixture `json:"credits"`
+}
+
+type creditFixture struct {
+	Person        string `json:"person"`
+	Role          string `json:"role"`
+	CharacterName string `json:"character_name"`
+	BillingOrder  int    `json:"billing_order"`
+}
+
+// Seed loads the curated genres, people and movies with their credits. It is
+// idempotent, records are matched on their natural key and never updated:
+// genres by slug, people by name, movies by title and release date, including
+// the trashed ones, and credits by movie, person and role. Movies are created
+// with their director credit, like through the API, but without history.
+func Seed(ctx context.Context, db *gorm.DB) (*Result, error) {
+	var genres []models.Genre
+	var people []models.Person
+	var movies []movieFixture
+	for name, fixtures := range map[string]interface{}{"genres.json": &genres, "people.json": &people, "movies.json": &movies} {
+		if err := loadFixtures(name, fixtures); err != nil {
+			return nil, err
+		}
+	}
+
+	result := new(Result)
+	movieRepository := repositories.NewMovieRepository(db)
+	err := repositories.NewTransactor(db).WithinTransaction(ctx, func(ctx context.Context) error {
+		tx := repositories.Conn(ctx, db)
+
+		genresBySlug, err := seedGenres(tx, genres, result)
+		if err != nil {
+			return err
+		}
+
+		for _, person := range people {
+			if errs := validators.ValidateStruct(&person); errs != nil {
+				return fmt.Errorf("person %q: %s", person.Name, strings.Join(errs, ", "))
+			}
+			created, err := firstOrCreate(tx, &person, "LOWER(name) = LOWER(?)", person.Name)
+			if err != nil {
+				return err
+			}
+			result.count(&result.People, created)
+		}
+
+		for _, fixture := range movies {
+			if err := seedMovie(ctx, tx, movieRepository, genresBySlug, fixture, result); err != nil {
+				return fmt.Errorf("movie %q: %w", fixture.Title, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// seedGenres creates the missing genres and returns every genre by slug.
+func seedGenres(tx *gorm.DB, genres []models.Genre, result *Result) (map[string]models.Genre, error) {
+	bySlug := make(map[string]models.Genre, len(genres))
+	for _, genre := range genres {
+		if errs := validators.ValidateStruct(&genre); errs != nil {
+			return nil, fmt.Errorf("genre %q: %s", genre.Slug, strings.Join(errs, ", "))
+		}
+		created, err := firstOrCreate(tx, &genre, "slug = ?", genre.Slug)
+		if err != nil {
+			return nil, err
+		}
+		result.count(&result.Genres, created)
+		bySlug[genre.Slug] = genre
+	}
+	return bySlug, nil
+}
+
+func seedMovie(ctx context.Context, tx *gorm.DB, movies repositories.MovieRepository, genresBySlug map[string]models.Genre, fixture movieFixture, result *Result) error {
+	movie := fixture.Movie
+
+	// resolve the genre slugs
+	for i, ref := range movie.Genres {
+		genre, ok := genresBySlug[ref.Slug]
+		if !ok {
+			return fmt.Errorf("unknown genre %q", ref.Slug)
+		}
+		movie.Genres[i] = genre
+	}
+	if errs := validators.ValidateStruct(&movie); errs != nil {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	// a trashed sample movie stays in the trash
+	existing, err := movies.GetByTitle(ctx, movie.Title, movie.ReleaseDate, repositories.WithTrashed())
+	switch {
+	case err == nil:
+		result.Skipped++
+		movie = *existing
+	case errors.Is(err, repositories.ErrNotFound):
+		if err := movies.Create(ctx, &movie); err != nil {
+			return err
+		}
+		result.Movies++
+	default:
+		return err
+	}
+
+	for _, fixture := range fixture.Credits {
+		// people missing from the people fixtures are created by name
+		person := models.Person{Name: fixture.Person}
+		created, err := firstOrCreate(tx, &person, "LOWER(name) = LOWER(?)", person.Name)
+		if err != nil {
+			return err
+		}
+		if created {
+			result.People++
+		}
+
+		credit := models.Credit{
+			MovieID:       movie.ID,
+			PersonID:      person.ID,
+			Role:          fixture.Role,
+			CharacterName: fixture.CharacterName,
+			BillingOrder:  fixture.BillingOrder,
+		}
+		if errs := validators.ValidateStruct(&credit); errs != nil {
+			return fmt.Errorf("credit of %q: %s", fixture.Person, strings.Join(errs, ", "))
+		}
+		created, err = firstOrCreate(tx, &credit, "movie_id = ? AND person_id = ? AND role = ?", credit.MovieID, credit.PersonID, credit.Role)
+		if err != nil {
+			return err
+		}
+		result.count(&result.Credits, created)
+	}
+	return nil
+}
+
+// firstOrCreate loads the record matching the natural key into dest, or
+// creates dest when there is none, and reports whether it was created.
+func firstOrCreate[T any](tx *gorm.DB, dest *T, query string, args ...interface{}) (bool, error) {
+	err := tx.Where(query, args...).Order("id").First(dest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, tx.Create(dest).Error
+	}
+	return false, err
+}
+
+// count counts a record firstOrCreate created, or skipped.
+func (r *Result) count(counter *int, created bool) {
+	if created {
+		*counter++
+	} else {
+		r.Skipped++
+	}
+}
+
+func loadFixtures(name string, dest interface{}) error {
+	content, err := fixtureFiles.ReadFile("fixtures/" + name)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, dest); err != nil {
+		return fmt.Errorf("fixtures %s: %w", name, err)
+	}
+	return nil
+}
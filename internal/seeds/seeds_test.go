@@ -0,0 +1,68 @@
+package seeds
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/zdacoder/go-fiber-movie-app-api/config/database"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/repositories"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestSeedTwice(t *testing.T) {
+	validators.Init()
+	db, err := gorm.Open(sqlite.Open("file::memory:?_pragma=foreign_keys(1)"), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection to an in-memory SQLite database opens a new one
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	// the migrations run on the database of the package
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+	ctx := context.Background()
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	first, err := Seed(ctx, db)
+	if err != nil {
+		t.Fatalf("first seed: %v", err)
+	}
+	if first.Genres == 0 || first.People == 0 || first.Movies == 0 || first.Credits == 0 || first.Skipped != 0 {
+		t.Fatalf("first seed = %+v, want every record created", first)
+	}
+
+	// a trashed sample movie is not created again
+	movies := repositories.NewMovieRepository(db)
+	var movie models.Movie
+	if err := db.Order("id").First(&movie).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := movies.Delete(ctx, &movie); err != nil {
+		t.Fatalf("trash %q: %v", movie.Title, err)
+	}
+
+	second, err := Seed(ctx, db)
+	if err != nil {
+		t.Fatalf("second seed: %v", err)
+	}
+	want := Result{Skipped: first.Genres + first.People + first.Movies + first.Credits}
+	if *second != want {
+		t.Errorf("second seed = %+v, want %+v", *second, want)
+	}
+	if _, err := movies.Get(ctx, movie.ID, repositories.OnlyTrashed()); err != nil {
+		t.Errorf("trashed movie after the second seed: %v", err)
+	}
+}
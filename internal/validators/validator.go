@@ -2,14 +2,28 @@ package validators
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 
 	"github.com/go-playground/validator/v10"
 )
 
 var validate *validator.Validate
 
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
 func Init() {
 	validate = validator.New()
+
+	validate.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		return slugPattern.MatchString(fl.Field().String())
+	})
+
+	// bcrypt limits passwords in bytes, max counts runes
+	validate.RegisterValidation("maxbytes", func(fl validator.FieldLevel) bool {
+		limit, err := strconv.Atoi(fl.Param())
+		return err == nil && len(fl.Field().String()) <= limit
+	})
 }
 
 var validationErrorsMessages = map[string]string{
@@ -21,10 +35,22 @@ var validationErrorsMessages = map[string]string{
 	"url":      "Invalid URL format",
 	"datetime": "Invalid date format, expected YYYY-MM-DD",
 	"numeric":  "This field must be a numeric value",
+	"oneof":    "Value is not one of the allowed options",
+	"gtefield": "Value must not be lower than its lower bound",
+	"slug":     "Only lowercase letters, digits and single hyphens are allowed",
+	"maxbytes": "Value exceeds the maximum length in bytes",
 }
 
 func ValidateStruct(s interface{}) []string {
-	err := validate.Struct(s)
+	return validationMessages(validate.Struct(s))
+}
+
+// ValidateStructPartial validates only the given struct fields.
+func ValidateStructPartial(s interface{}, fields ...string) []string {
+	return validationMessages(validate.StructPartial(s, fields...))
+}
+
+func validationMessages(err error) []string {
 	if err == nil {
 		return nil
 	}
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/bytedance/sonic"
 	"github.com/gofiber/fiber/v2"
@@ -9,12 +14,29 @@ import (
 	"github.com/zdacoder/go-fiber-movie-app-api/config"
 	"github.com/zdacoder/go-fiber-movie-app-api/config/database"
 	_ "github.com/zdacoder/go-fiber-movie-app-api/docs"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/commands"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/handlers"
+	"github.com/zdacoder/go-fiber-movie-app-api/internal/jobs"
 	"github.com/zdacoder/go-fiber-movie-app-api/internal/models"
 	"github.com/zdacoder/go-fiber-movie-app-api/internal/routes"
 	"github.com/zdacoder/go-fiber-movie-app-api/internal/validators"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/cursor"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/health"
 	"github.com/zdacoder/go-fiber-movie-app-api/pkg/logger"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/shutdown"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/telemetry"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/token"
 )
 
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 Access token from /api/auth/login, sent as "Bearer <token>", API keys are accepted as "Bearer mk_..." too
+
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        X-API-Key
+// @description                 API key for server-to-server integrations
 func main() {
 	// Load environment variables
 	config := config.Load()
@@ -22,24 +44,69 @@ func main() {
 	// Initialize logger
 	logger.Init(config)
 
+	// Run a command instead of the server, e.g. "server migrate up"
+	if len(os.Args) > 1 {
+		if err := commands.Run(config, os.Args[1:]); err != nil {
+			log.Fatal().Err(err).Msg("Command failed")
+		}
+		return
+	}
+
+	// Initialize tracing, before the database so that queries are traced
+	telemetry.Init(config)
+
 	// Initialize database connection
 	database.Connect(config)
 
-	// Run database migrations
-	database.Migrate(&models.Movie{})
+	// Run database migrations, or let GORM create the tables of the models in development
+	if config.DBAutoMigrate {
+		database.AutoMigrate(&models.Genre{}, &models.Movie{}, &models.Person{}, &models.Credit{}, &models.MovieRevision{}, &models.User{}, &models.RefreshToken{}, &models.RevokedToken{}, &models.APIKey{}, &models.ImportJob{})
+	} else if config.DBMigrateOnStart {
+		database.Migrate()
+	} else {
+		// migrations run out of band, readiness waits for them
+		health.Register("migrations", database.CheckMigrations)
+	}
 
 	// Create a new Fiber instance
 	app := fiber.New(fiber.Config{
 		// Prefork:     true,
 		JSONEncoder: sonic.Marshal,
 		JSONDecoder: sonic.Unmarshal,
+		// client IPs come from the proxy header of trusted proxies only
+		ProxyHeader:             config.ProxyHeader,
+		TrustedProxies:          config.TrustedProxies,
+		EnableTrustedProxyCheck: true,
+		EnableIPValidation:      true,
 	})
 
 	// validation initialization
 	validators.Init()
 
+	// pagination cursor signing initialization
+	cursor.Init(config)
+
+	// authentication token signing initialization
+	token.Init(config)
+
+	// readiness check timeout initialization
+	health.Init(config)
+
+	// Handlers, backed by the database
+	h := handlers.New(database.DB, config)
+	shutdown.Register("movie imports", h.StopImports)
+
 	// Initialize routes
-	routes.Init(app)
+	routes.Init(app, config, h)
+
+	// Start purging expired movies from the trash
+	jobs.StartTrashPurge(config, h)
+
+	// Start deleting expired authentication tokens
+	jobs.StartTokenCleanup(h)
+
+	// Start refreshing the business metrics
+	jobs.StartMetricsRefresh(config, h)
 
 	// Construct server address and start the server
 	addr := fmt.Sprintf("%s:%s", config.ServerHost, config.ServerPort)
@@ -48,7 +115,34 @@ func main() {
 	log.Info().Msgf("Starting server on %s in %s mode", addr, config.AppEnv)
 
 	// If server fails to start, log the error and exit
-	if err := app.Listen(addr); err != nil {
-		log.Fatal().Err(err).Msg("Failed to start server")
+	go func() {
+		if err := app.Listen(addr); err != nil {
+			log.Fatal().Err(err).Msg("Failed to start server")
+		}
+	}()
+
+	// Wait for a termination signal, a second one kills the process
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	sig := <-quit
+	signal.Stop(quit)
+	log.Info().Str("signal", sig.String()).Msg("Shutting down server")
+
+	// Fail readiness first, so that load balancers stop sending new requests
+	health.Drain()
+	time.Sleep(config.ShutdownDelay)
+
+	// Stop accepting connections and drain the in-flight requests
+	if err := app.ShutdownWithTimeout(config.ShutdownTimeout); err != nil {
+		log.Error().Err(err).Msg("Failed to drain in-flight requests")
+	}
+
+	// Stop the background jobs and close the connections
+	ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	if err := shutdown.Run(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to shut down cleanly")
 	}
+
+	log.Info().Msg("Server stopped")
 }
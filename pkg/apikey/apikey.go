@@ -0,0 +1,53 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// Scheme is the prefix telling API keys apart from JWTs.
+const Scheme = "mk_"
+
+// prefixLength is the length of the public key prefix, the scheme included.
+const prefixLength = len(Scheme) + 8
+
+// Generate returns a new API key, its public prefix and the hash to store.
+// The key has the form mk_<8 hex chars>_<secret>.
+func Generate() (key, prefix, hash string, err error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = Scheme + hex.EncodeToString(id)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, Hash(key), nil
+}
+
+// Hash returns the SHA-256 digest of the key, keys are random enough not to
+// need a slow password hash.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Prefix returns the public prefix of a key, false when it is not an API key.
+func Prefix(key string) (string, bool) {
+	if !strings.HasPrefix(key, Scheme) || len(key) <= prefixLength || key[prefixLength] != '_' {
+		return "", false
+	}
+	return key[:prefixLength], true
+}
+
+// Matches reports in constant time whether the key hashes to the stored hash.
+func Matches(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(Hash(key)), []byte(hash)) == 1
+}
@@ -0,0 +1,84 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+var secret []byte
+
+// Cursor marks a position in a keyset paginated result set.
+type Cursor struct {
+	Sort     string `json:"s"`
+	Desc     bool   `json:"d"`
+	Value    string `json:"v"`
+	ID       uint   `json:"i"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+func Init(config *config.Config) {
+	if config.CursorSecret != "" {
+		secret = []byte(config.CursorSecret)
+		return
+	}
+
+	// a random secret differs between replicas and restarts, which invalidates every cursor
+	if config.IsProduction() {
+		log.Fatal().Msg("CURSOR_SECRET is required in production")
+	}
+
+	// without a configured secret cursors only stay valid until the next restart
+	secret = make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatal().Err(err).Msg("Failed to generate cursor secret")
+	}
+	log.Warn().Msg("CURSOR_SECRET is not set, using a random secret")
+}
+
+// Encode serializes and signs the cursor into an opaque token.
+func Encode(c Cursor) string {
+	payload, _ := json.Marshal(c)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(sign(encoded))
+}
+
+// Decode verifies the token signature and deserializes the cursor.
+func Decode(token string) (*Cursor, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, sign(encoded)) {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	c := new(Cursor)
+	if err := json.Unmarshal(payload, c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+func sign(data string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
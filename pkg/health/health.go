@@ -0,0 +1,113 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+)
+
+// Check statuses.
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check verifies that a dependency is usable, it must honor the context deadline.
+type Check func(ctx context.Context) error
+
+// CheckResult is the outcome of a single check.
+type CheckResult struct {
+	Status    string  `json:"status" example:"up"`
+	LatencyMs float64 `json:"latency_ms" example:"1.42"`
+	Error     string  `json:"error,omitempty" example:"context deadline exceeded"`
+}
+
+// Report is the outcome of every readiness check, up when all of them are.
+type Report struct {
+	Status string                 `json:"status" example:"up"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+var (
+	mu       sync.RWMutex
+	checks   = map[string]Check{}
+	timeout  = 2 * time.Second
+	draining atomic.Bool
+)
+
+func Init(config *config.Config) {
+	if config.HealthCheckTimeout > 0 {
+		timeout = config.HealthCheckTimeout
+	}
+}
+
+// Register adds a readiness check, replacing the check of the same name.
+func Register(name string, check Check) {
+	mu.Lock()
+	defer mu.Unlock()
+	checks[name] = check
+}
+
+// Drain makes readiness fail from now on, so that load balancers stop
+// routing new requests to the server before it shuts down.
+func Drain() {
+	draining.Store(true)
+}
+
+// Run runs every registered check concurrently, each one bounded by the
+// configured timeout.
+func Run(ctx context.Context) Report {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]CheckResult, len(checks)+1)}
+	if draining.Load() {
+		report.Status = StatusDown
+		report.Checks["server"] = CheckResult{Status: StatusDown, Error: "shutting down"}
+	}
+
+	var (
+		wg      sync.WaitGroup
+		results sync.Mutex
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := run(ctx, check)
+
+			results.Lock()
+			defer results.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+func run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := CheckResult{
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+// WithRequest returns a context carrying the request ID and a logger that
+// adds it to every line, together with the IDs of the current trace and span.
+func WithRequest(ctx context.Context, requestID string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	fields := log.Logger.With().Str("request_id", requestID)
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		fields = fields.Str("trace_id", span.TraceID().String()).Str("span_id", span.SpanID().String())
+	}
+	logger := fields.Logger()
+	return logger.WithContext(ctx)
+}
+
+// RequestID returns the request ID carried by the context, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Ctx returns the logger of the context, the global logger when it has none.
+func Ctx(ctx context.Context) *zerolog.Logger {
+	return zerolog.Ctx(ctx)
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// slowQueryThreshold is the duration above which queries are logged as slow.
+const slowQueryThreshold = 200 * time.Millisecond
+
+// GormLogger routes GORM logs through the zerolog logger of the query
+// context, so queries carry the request ID of the request that ran them.
+// Every query is logged at debug level, slow queries at warn level and
+// failed ones at error level.
+type GormLogger struct {
+	level gormlogger.LogLevel
+}
+
+func NewGormLogger() *GormLogger {
+	return &GormLogger{level: gormlogger.Info}
+}
+
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	return &GormLogger{level: level}
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		Ctx(ctx).Info().Msgf(msg, args...)
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		Ctx(ctx).Warn().Msgf(msg, args...)
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		Ctx(ctx).Error().Msgf(msg, args...)
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	logger := Ctx(ctx)
+
+	var event *zerolog.Event
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		event = logger.Error().Err(err)
+	case elapsed > slowQueryThreshold && l.level >= gormlogger.Warn:
+		event = logger.Warn().Bool("slow", true)
+	case l.level >= gormlogger.Info:
+		event = logger.Debug()
+	default:
+		return
+	}
+
+	sql, rows := fc()
+	event.Str("sql", sql).Int64("rows", rows).Dur("duration", elapsed).Msg("Database query")
+}
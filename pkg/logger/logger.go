@@ -39,5 +39,8 @@ func Init(config *config.Config) {
 
 	log.Logger = log.Logger.With().Str("service", "movie-app-api").Logger()
 
+	// contexts without a request logger fall back to the global logger
+	zerolog.DefaultContextLogger = &log.Logger
+
 	log.Info().Msgf("Logger initialized with level: %s", level)
 }
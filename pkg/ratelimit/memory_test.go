@@ -0,0 +1,38 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	testStore(t, store, Limit{Requests: 3, Period: 3 * time.Second}, func(d time.Duration) { now = now.Add(d) }, 0)
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 3, Period: 3 * time.Second}
+
+	if _, err := store.Take(context.Background(), "a", limit); err != nil {
+		t.Fatal(err)
+	}
+
+	// full buckets are dropped once a sweep is due
+	now = now.Add(sweepInterval)
+	if _, err := store.Take(context.Background(), "b", limit); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.buckets["a"]; ok {
+		t.Error("refilled bucket was not swept")
+	}
+	if _, ok := store.buckets["b"]; !ok {
+		t.Error("bucket in use was swept")
+	}
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Route groups with their own limits.
+const (
+	GroupDefault = "default"
+	GroupSearch  = "search"
+	GroupAuth    = "auth"
+	// GroupAuthFailure counts the requests rejected for invalid credentials.
+	GroupAuthFailure = "auth_failure"
+)
+
+// Identities requests are limited by.
+const (
+	IdentityIP     = "ip"
+	IdentityUser   = "user"
+	IdentityAPIKey = "api_key"
+)
+
+// Limit is a token bucket holding up to Requests tokens that refills
+// completely over Period. A zero limit disables limiting.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit parses a limit written as "<requests>/<period>", such as
+// "60/1m", or "0" to disable limiting.
+func ParseLimit(value string) (Limit, error) {
+	if strings.TrimSpace(value) == "0" {
+		return Limit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected <requests>/<period>", value)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n < 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit requests %q", requests)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit period %q", period)
+	}
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// Enabled reports whether the limit restricts anything.
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// rate returns the tokens added per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed bool
+	Limit   int
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token, set when not allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps token buckets, the memory store suits a single node and the
+// Redis store shares the buckets across a cluster.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// Peek reports whether a token could be taken, without taking it.
+	Peek(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// result describes a bucket left with the given tokens after a take.
+func result(limit Limit, tokens float64, allowed bool) Result {
+	rate := limit.rate()
+	r := Result{
+		Allowed:   allowed,
+		Limit:     limit.Requests,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(limit.Requests) - tokens) / rate),
+	}
+	if !allowed {
+		r.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return r
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Limit
+		err   bool
+	}{
+		{value: "60/1m", want: Limit{Requests: 60, Period: time.Minute}},
+		{value: " 10 / 30s ", want: Limit{Requests: 10, Period: 30 * time.Second}},
+		{value: "0", want: Limit{}},
+		{value: "60", err: true},
+		{value: "-1/1m", err: true},
+		{value: "60/0s", err: true},
+		{value: "60/minute", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.value)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseLimit(%q) = %v, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLimit(%q) = %v, %v, want %v", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+// testStore drains a bucket of three tokens and checks its refill, Reset and
+// RetryAfter. advance lets the time pass, durations are compared within
+// tolerance for stores using the wall clock.
+func testStore(t *testing.T, store Store, limit Limit, advance func(time.Duration), tolerance time.Duration) {
+	t.Helper()
+	ctx := context.Background()
+	step := limit.Period / time.Duration(limit.Requests)
+
+	take := func(key string) Result {
+		t.Helper()
+		r, err := store.Take(ctx, key, limit)
+		if err != nil {
+			t.Fatalf("Take(%q): %v", key, err)
+		}
+		if r.Limit != limit.Requests {
+			t.Errorf("Limit = %d, want %d", r.Limit, limit.Requests)
+		}
+		return r
+	}
+	within := func(name string, got, want time.Duration) {
+		t.Helper()
+		if got < want-tolerance || got > want+tolerance {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+
+	// peeking costs nothing
+	if r, err := store.Peek(ctx, "a", limit); err != nil || !r.Allowed || r.Remaining != limit.Requests {
+		t.Fatalf("Peek on a full bucket = %+v, %v, want allowed with %d remaining", r, err, limit.Requests)
+	}
+
+	// the bucket starts full and every take costs a token
+	for i := 1; i <= limit.Requests; i++ {
+		r := take("a")
+		if !r.Allowed || r.Remaining != limit.Requests-i {
+			t.Fatalf("take %d = allowed %t remaining %d, want allowed with %d remaining", i, r.Allowed, r.Remaining, limit.Requests-i)
+		}
+		within("Reset", r.Reset, time.Duration(i)*step)
+		if r.RetryAfter != 0 {
+			t.Errorf("RetryAfter = %s on an allowed take", r.RetryAfter)
+		}
+	}
+
+	// an empty bucket denies until the next token
+	r := take("a")
+	if r.Allowed || r.Remaining != 0 {
+		t.Fatalf("take on an empty bucket = allowed %t remaining %d, want denied", r.Allowed, r.Remaining)
+	}
+	within("RetryAfter", r.RetryAfter, step)
+	within("Reset", r.Reset, limit.Period)
+	if r, err := store.Peek(ctx, "a", limit); err != nil || r.Allowed {
+		t.Errorf("Peek on an empty bucket = %+v, %v, want denied", r, err)
+	}
+
+	// other keys have their own bucket
+	if r := take("b"); !r.Allowed || r.Remaining != limit.Requests-1 {
+		t.Errorf("take on another key = allowed %t remaining %d, want allowed with %d remaining", r.Allowed, r.Remaining, limit.Requests-1)
+	}
+
+	// a token refills over a step
+	advance(step)
+	if r := take("a"); !r.Allowed || r.Remaining != 0 {
+		t.Errorf("take after a step = allowed %t remaining %d, want allowed with 0 remaining", r.Allowed, r.Remaining)
+	}
+
+	// the bucket refills up to its capacity
+	advance(2 * limit.Period)
+	r = take("a")
+	if !r.Allowed || r.Remaining != limit.Requests-1 {
+		t.Errorf("take after a refill = allowed %t remaining %d, want allowed with %d remaining", r.Allowed, r.Remaining, limit.Requests-1)
+	}
+	within("Reset", r.Reset, step)
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript refills a bucket and takes the cost from it atomically when it
+// holds a token. The Redis clock is used so that every node agrees on the
+// time. It returns whether a token was available and the tokens left.
+var takeScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1])
+local updated = tonumber(state[2])
+if tokens == nil or updated == nil then
+	tokens = capacity
+	updated = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - updated) * capacity / period)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - cost
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', now)
+redis.call('PEXPIRE', KEYS[1], period)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore keeps token buckets in Redis so that limits hold across nodes.
+type RedisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+func NewRedisStore(client redis.Scripter, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	return s.take(ctx, key, limit, 1)
+}
+
+func (s *RedisStore) Peek(ctx context.Context, key string, limit Limit) (Result, error) {
+	return s.take(ctx, key, limit, 0)
+}
+
+func (s *RedisStore) take(ctx context.Context, key string, limit Limit, cost int) (Result, error) {
+	reply, err := takeScript.Run(ctx, s.client, []string{s.prefix + key}, limit.Requests, limit.Period.Milliseconds(), cost).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	allowed, _ := reply[0].(int64)
+	left, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(left, 64)
+	if err != nil {
+		return Result{}, err
+	}
+	return result(limit, tokens, allowed == 1), nil
+}
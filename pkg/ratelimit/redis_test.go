@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TestRedisStore runs against the Redis server of REDIS_URL, it is skipped
+// when the variable is not set.
+func TestRedisStore(t *testing.T) {
+	url := os.Getenv("REDIS_URL")
+	if url == "" {
+		t.Skip("REDIS_URL is not set")
+	}
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		t.Fatalf("invalid REDIS_URL: %v", err)
+	}
+	client := redis.NewClient(options)
+	t.Cleanup(func() { client.Close() })
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("Redis is unreachable: %v", err)
+	}
+
+	// the buckets of every run are apart and expire after their period
+	prefix := "ratelimit-test:" + strconv.FormatInt(time.Now().UnixNano(), 10) + ":"
+	store := NewRedisStore(client, prefix)
+
+	// Redis keeps its own clock, the time passes for real
+	testStore(t, store, Limit{Requests: 3, Period: 600 * time.Millisecond}, time.Sleep, 50*time.Millisecond)
+}
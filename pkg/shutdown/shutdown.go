@@ -0,0 +1,53 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Hook releases the resources of a subsystem, it must honor the context deadline.
+type Hook func(ctx context.Context) error
+
+type hook struct {
+	name string
+	run  Hook
+}
+
+var (
+	mu    sync.Mutex
+	hooks []hook
+)
+
+// Register adds a cleanup hook. Hooks run in reverse order of registration,
+// so a subsystem is stopped before the ones it was started on top of.
+func Register(name string, run Hook) {
+	mu.Lock()
+	defer mu.Unlock()
+	hooks = append(hooks, hook{name: name, run: run})
+}
+
+// Run runs every registered hook once, in reverse order of registration,
+// and returns the errors of the failed ones. A failing hook does not keep
+// the next ones from running.
+func Run(ctx context.Context) error {
+	mu.Lock()
+	pending := hooks
+	hooks = nil
+	mu.Unlock()
+
+	var errs []error
+	for i := len(pending) - 1; i >= 0; i-- {
+		h := pending[i]
+		if err := h.run(ctx); err != nil {
+			log.Error().Err(err).Str("hook", h.name).Msg("Shutdown hook failed")
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			continue
+		}
+		log.Info().Str("hook", h.name).Msg("Shutdown hook completed")
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,117 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/shutdown"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing exporters supported by TRACING_EXPORTER.
+const (
+	ExporterNone     = "none"
+	ExporterStdout   = "stdout"
+	ExporterOTLPGRPC = "otlp-grpc"
+	ExporterOTLPHTTP = "otlp-http"
+)
+
+// ServiceName identifies the service in traces.
+const ServiceName = "movie-app-api"
+
+var provider *sdktrace.TracerProvider
+
+// Init installs the global tracer provider and the W3C trace context
+// propagator. Incoming sampling decisions are honored, root spans are
+// sampled with the configured ratio. Without an exporter spans are not
+// recorded, but trace context is still propagated.
+func Init(config *config.Config) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if config.TracingExporter == ExporterNone {
+		log.Info().Msg("Tracing disabled")
+		return
+	}
+
+	exporter, err := newExporter(config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create tracing exporter")
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+		semconv.DeploymentEnvironment(config.AppEnv),
+	))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create tracing resource")
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.TracingSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	// flush the pending spans last, they include the ones of the shutdown
+	shutdown.Register("tracing", Shutdown)
+
+	log.Info().Str("exporter", config.TracingExporter).Float64("sample_ratio", config.TracingSampleRatio).Msg("Tracing initialized")
+}
+
+func newExporter(config *config.Config) (sdktrace.SpanExporter, error) {
+	ctx := context.Background()
+
+	switch config.TracingExporter {
+	case ExporterStdout:
+		return stdouttrace.New()
+	case ExporterOTLPGRPC:
+		options := []otlptracegrpc.Option{}
+		if config.TracingEndpoint != "" {
+			options = append(options, otlptracegrpc.WithEndpoint(config.TracingEndpoint))
+		}
+		if config.TracingInsecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, options...)
+	case ExporterOTLPHTTP:
+		options := []otlptracehttp.Option{}
+		if config.TracingEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(config.TracingEndpoint))
+		}
+		if config.TracingInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", config.TracingExporter)
+	}
+}
+
+// Enabled reports whether spans are exported.
+func Enabled() bool {
+	return provider != nil
+}
+
+// Tracer returns the tracer of the service.
+func Tracer() trace.Tracer {
+	return otel.Tracer(ServiceName)
+}
+
+// Shutdown flushes the pending spans and stops the exporter.
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	return provider.Shutdown(ctx)
+}
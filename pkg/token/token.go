@@ -0,0 +1,135 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/zdacoder/go-fiber-movie-app-api/config"
+)
+
+const (
+	TypeAccess  = "access"
+	TypeRefresh = "refresh"
+
+	issuer = "go-fiber-movie-app-api"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+var (
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+)
+
+// Claims are the claims carried by access and refresh tokens.
+type Claims struct {
+	Role string `json:"role"`
+	Type string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// UserID returns the ID of the user the token was issued to.
+func (c *Claims) UserID() uint {
+	id, _ := strconv.ParseUint(c.Subject, 10, 64)
+	return uint(id)
+}
+
+// Pair is the access and refresh token pair returned to clients.
+type Pair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int    `json:"expires_in" example:"900"`
+}
+
+func Init(config *config.Config) {
+	accessTTL = config.AccessTokenTTL
+	refreshTTL = config.RefreshTokenTTL
+
+	if config.JWTSecret != "" {
+		secret = []byte(config.JWTSecret)
+		return
+	}
+
+	// a random secret differs between replicas and restarts, which invalidates every token
+	if config.IsProduction() {
+		log.Fatal().Msg("JWT_SECRET is required in production")
+	}
+
+	// without a configured secret tokens only stay valid until the next restart
+	secret = make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatal().Err(err).Msg("Failed to generate JWT secret")
+	}
+	log.Warn().Msg("JWT_SECRET is not set, using a random secret")
+}
+
+// Issue signs a token of the given type for the user and returns it with its claims.
+func Issue(userID uint, role, typ string) (string, *Claims, error) {
+	ttl := accessTTL
+	if typ == TypeRefresh {
+		ttl = refreshTTL
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		Role: role,
+		Type: typ,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(id),
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			Issuer:    issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
+// NewPair issues an access and a refresh token for the user, the claims of the
+// refresh token are returned so it can be stored for rotation.
+func NewPair(userID uint, role string) (*Pair, *Claims, error) {
+	access, _, err := Issue(userID, role, TypeAccess)
+	if err != nil {
+		return nil, nil, err
+	}
+	refresh, refreshClaims, err := Issue(userID, role, TypeRefresh)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &Pair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(accessTTL.Seconds()),
+	}, refreshClaims, nil
+}
+
+// Parse verifies a token of the given type and returns its claims.
+func Parse(raw, typ string) (*Claims, error) {
+	claims := new(Claims)
+	_, err := jwt.ParseWithClaims(raw, claims, func(*jwt.Token) (interface{}, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(issuer), jwt.WithExpirationRequired())
+	if err != nil || claims.Type != typ || claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
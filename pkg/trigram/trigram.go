@@ -0,0 +1,98 @@
+// Package trigram measures how similar strings are by the trigrams they
+// share, like the pg_trgm extension of PostgreSQL, for the databases that do
+// not have it.
+package trigram
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Threshold is the word similarity at which strings match, the default
+// pg_trgm.word_similarity_threshold.
+const Threshold = 0.6
+
+// words splits s into its lowercase runs of letters and digits, the words
+// pg_trgm extracts trigrams from.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// trigrams returns the trigrams of the words of s in order, with duplicates.
+// Words are padded with two spaces in front and one behind, so that their
+// beginnings weigh more than their ends.
+func trigrams(s string) []string {
+	var trigrams []string
+	for _, word := range words(s) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			trigrams = append(trigrams, string(padded[i:i+3]))
+		}
+	}
+	return trigrams
+}
+
+// set returns the distinct trigrams of s.
+func set(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, trigram := range trigrams(s) {
+		set[trigram] = true
+	}
+	return set
+}
+
+// WordSimilarity returns the greatest similarity between the trigrams of
+// needle and those of a continuous extent of text, from 0 to 1, like the
+// word_similarity function of pg_trgm.
+func WordSimilarity(needle, text string) float64 {
+	wanted := set(needle)
+	if len(wanted) == 0 {
+		return 0
+	}
+
+	best := 0.0
+	sequence := trigrams(text)
+	for start := range sequence {
+		extent := make(map[string]bool)
+		shared := 0
+		for _, trigram := range sequence[start:] {
+			if extent[trigram] {
+				continue
+			}
+			extent[trigram] = true
+			if wanted[trigram] {
+				shared++
+			}
+			similarity := float64(shared) / float64(len(wanted)+len(extent)-shared)
+			best = max(best, similarity)
+		}
+	}
+	return best
+}
+
+// Required returns the unpadded trigrams of needle, which LIKE patterns can
+// find, and how many of them a text must contain to reach the word
+// similarity threshold. It returns none when needle has no word of three
+// characters or more.
+func Required(needle string, threshold float64) ([]string, int) {
+	var inner []string
+	wanted := set(needle)
+	for trigram := range wanted {
+		if !strings.Contains(trigram, " ") {
+			inner = append(inner, trigram)
+		}
+	}
+	if len(inner) == 0 {
+		return nil, 0
+	}
+	slices.Sort(inner)
+
+	// every padded trigram may be shared, the rest must be inner ones
+	least := int(math.Ceil(threshold*float64(len(wanted)) - 1e-9))
+	least -= len(wanted) - len(inner)
+	return inner, max(least, 1)
+}
@@ -0,0 +1,42 @@
+package trigram
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestWordSimilarity(t *testing.T) {
+	tests := []struct {
+		needle, text string
+		want         float64
+	}{
+		{"word", "two words", 0.8},
+		{"godfather", "The Godfather", 1},
+		{"godfater", "The Godfather", 6.0 / 9},
+		{"GOD", "the godfather", 0.75},
+		{"godfater", "Francis Ford Coppola", 0},
+		{"", "The Godfather", 0},
+		{"alien", "", 0},
+	}
+	for _, tt := range tests {
+		if got := WordSimilarity(tt.needle, tt.text); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("WordSimilarity(%q, %q) = %f, want %f", tt.needle, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRequired(t *testing.T) {
+	trigrams, least := Required("godfater", Threshold)
+	if want := []string{"ate", "dfa", "fat", "god", "odf", "ter"}; !slices.Equal(trigrams, want) || least != 3 {
+		t.Errorf("Required(godfater) = %v, %d, want %v, 3", trigrams, least, want)
+	}
+
+	// a text sharing fewer inner trigrams cannot reach the threshold
+	if WordSimilarity("godfater", "dfa fat") >= Threshold {
+		t.Error("a text with two of the inner trigrams reaches the threshold")
+	}
+	if trigrams, least := Required("al", Threshold); trigrams != nil || least != 0 {
+		t.Errorf("Required(al) = %v, %d, want none", trigrams, least)
+	}
+}
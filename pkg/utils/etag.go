@@ -0,0 +1,27 @@
+package utils
+
+import "strings"
+
+// MatchesETag reports whether an If-Match or If-None-Match header value
+// matches the entity tag. If-Match requires the strong comparison, weak tags
+// never match it, while If-None-Match uses the weak comparison.
+func MatchesETag(header, etag string, weak bool) bool {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Pagination struct {
+	Total      int64  `json:"total" example:"120"`
+	Page       int    `json:"page" example:"1"`
+	PerPage    int    `json:"per_page" example:"20"`
+	TotalPages int    `json:"total_pages" example:"6"`
+	Next       string `json:"next,omitempty" example:"http://localhost:3000/api/movies?page=2&per_page=20"`
+	Prev       string `json:"prev,omitempty"`
+}
+
+func NewPagination(ctx *fiber.Ctx, page, perPage int, total int64) Pagination {
+	totalPages := int((total + int64(perPage) - 1) / int64(perPage))
+
+	pagination := Pagination{
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages,
+	}
+
+	if page < totalPages {
+		pagination.Next = pageURL(ctx, page+1, perPage)
+	}
+	if page > 1 {
+		pagination.Prev = pageURL(ctx, min(page-1, max(totalPages, 1)), perPage)
+	}
+
+	return pagination
+}
+
+// pageURL rebuilds the current request URL pointing at the given page.
+func pageURL(ctx *fiber.Ctx, page, perPage int) string {
+	query, _ := url.ParseQuery(string(ctx.Request().URI().QueryString()))
+	query.Del("limit")
+	query.Del("offset")
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+
+	return ctx.BaseURL() + ctx.Path() + "?" + query.Encode()
+}
+
+type CursorPagination struct {
+	PerPage    int    `json:"per_page" example:"20"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
@@ -1,19 +1,24 @@
 package utils
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/zdacoder/go-fiber-movie-app-api/pkg/logger"
+)
 
 type SuccessResponse struct {
 	Code    int         `json:"code" example:"200"`
 	Status  string      `json:"status" example:"success"`
 	Message string      `json:"message" example:"Request successfully processed"`
 	Data    interface{} `json:"data"`
+	Meta    interface{} `json:"meta,omitempty"`
 }
 
 type ErrorResponse struct {
-	Code    int         `json:"code" example:"400"`
-	Status  string      `json:"status" example:"error"`
-	Message string      `json:"message" example:"Invalid request parameters"`
-	Error   interface{} `json:"error"`
+	Code      int         `json:"code" example:"400"`
+	Status    string      `json:"status" example:"error"`
+	Message   string      `json:"message" example:"Invalid request parameters"`
+	Error     interface{} `json:"error"`
+	RequestID string      `json:"request_id,omitempty" example:"3f1c2a8e-5b7d-4c1e-9a2f-6d8e0b4c7a91"`
 }
 
 func NewSuccessResponse(send *fiber.Ctx, code int, message string, data interface{}) error {
@@ -27,12 +32,30 @@ func NewSuccessResponse(send *fiber.Ctx, code int, message string, data interfac
 	return send.Status(code).JSON(response)
 }
 
-func NewErrorResponse(send *fiber.Ctx, code int, message string, err interface{}) error {
-	response := ErrorResponse{
+func NewPaginatedResponse(send *fiber.Ctx, code int, message string, data interface{}, meta interface{}) error {
+	response := SuccessResponse{
 		Code:    code,
-		Status:  "error",
+		Status:  "success",
 		Message: message,
-		Error:   err,
+		Data:    data,
+		Meta:    meta,
+	}
+
+	return send.Status(code).JSON(response)
+}
+
+func NewErrorResponse(send *fiber.Ctx, code int, message string, err interface{}) error {
+	response := ErrorResponse{
+		Code:      code,
+		Status:    "error",
+		Message:   message,
+		Error:     err,
+		RequestID: logger.RequestID(send.UserContext()),
+	}
+
+	// server errors are logged with the request ID the client receives
+	if code >= 500 {
+		logger.Ctx(send.UserContext()).Error().Int("status", code).Interface("error", err).Msg(message)
 	}
 
 	return send.Status(code).JSON(response)
@@ -42,10 +65,18 @@ func OKResponse(ctx *fiber.Ctx, message string, data interface{}) error {
 	return NewSuccessResponse(ctx, 200, message, data)
 }
 
+func PaginatedResponse(ctx *fiber.Ctx, message string, data interface{}, meta interface{}) error {
+	return NewPaginatedResponse(ctx, 200, message, data, meta)
+}
+
 func CreatedResponse(ctx *fiber.Ctx, message string, data interface{}) error {
 	return NewSuccessResponse(ctx, 201, message, data)
 }
 
+func AcceptedResponse(ctx *fiber.Ctx, message string, data interface{}) error {
+	return NewSuccessResponse(ctx, 202, message, data)
+}
+
 func NoContentResponse(ctx *fiber.Ctx, message string) error {
 	return NewSuccessResponse(ctx, 200, message, nil)
 }
@@ -54,10 +85,42 @@ func BadRequestResponse(ctx *fiber.Ctx, message string, err interface{}) error {
 	return NewErrorResponse(ctx, 400, message, err)
 }
 
+func UnauthorizedResponse(ctx *fiber.Ctx, message string, err interface{}) error {
+	return NewErrorResponse(ctx, 401, message, err)
+}
+
+func ForbiddenResponse(ctx *fiber.Ctx, message string, err interface{}) error {
+	return NewErrorResponse(ctx, 403, message, err)
+}
+
 func NotFoundResponse(ctx *fiber.Ctx, message string, err interface{}) error {
 	return NewErrorResponse(ctx, 404, message, err)
 }
 
+func ConflictResponse(ctx *fiber.Ctx, message string, err interface{}) error {
+	return NewErrorResponse(ctx, 409, message, err)
+}
+
+func PreconditionFailedResponse(ctx *fiber.Ctx, message string, err interface{}) error {
+	return NewErrorResponse(ctx, 412, message, err)
+}
+
+func TooManyRequestsResponse(ctx *fiber.Ctx, message string, err interface{}) error {
+	return NewErrorResponse(ctx, 429, message, err)
+}
+
+func PreconditionRequiredResponse(ctx *fiber.Ctx, message string, err interface{}) error {
+	return NewErrorResponse(ctx, 428, message, err)
+}
+
+func UnsupportedMediaTypeResponse(ctx *fiber.Ctx, message string, err interface{}) error {
+	return NewErrorResponse(ctx, 415, message, err)
+}
+
 func InternalServerErrorResponse(ctx *fiber.Ctx, message string, err interface{}) error {
 	return NewErrorResponse(ctx, 500, message, err)
 }
+
+func ServiceUnavailableResponse(ctx *fiber.Ctx, message string, err interface{}) error {
+	return NewErrorResponse(ctx, 503, message, err)
+}
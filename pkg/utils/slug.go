@@ -0,0 +1,14 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify lowercases the value and joins its words with hyphens.
+func Slugify(value string) string {
+	slug := nonSlugCharacters.ReplaceAllString(strings.ToLower(strings.TrimSpace(value)), "-")
+	return strings.Trim(slug, "-")
+}